@@ -17,13 +17,19 @@ limitations under the License.
 package v1
 
 import (
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // BackupSpec defines the desired state of Backup
+// +kubebuilder:validation:XValidation:rule="!(has(self.schedule) && has(self.schedules))",message="schedule and schedules are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!has(self.schedules) || !has(self.runOnChange) || self.runOnChange == 'Never'",message="runOnChange is not supported with schedules"
+// +kubebuilder:validation:XValidation:rule="!has(self.deletionPolicy) || self.deletionPolicy != 'DeleteArtifacts' || !has(self.storageRefs) || self.storageRefs.all(s, !has(s.type) || !(s.type.lowerAscii() in ['gcs', 'azure', 'upyun']))",message="deletionPolicy DeleteArtifacts is not supported with gcs, azure and upyun storages"
 type BackupSpec struct {
 	// DatabaseRefs represents the list of databases to backup
 	DatabaseRefs []DatabaseRef `json:"databaseRefs,omitempty"`
@@ -31,6 +37,14 @@ type BackupSpec struct {
 	// StorageRefs represents the list of storages to backup to
 	StorageRefs []StorageRef `json:"storageRefs,omitempty"`
 
+	// Archives lists PersistentVolumeClaims, in the same namespace, whose
+	// files are archived along with the databases
+	// +optional
+	// +listType=map
+	// +listMapKey=claimName
+	// +kubebuilder:validation:MaxItems=20
+	Archives []BackupArchive `json:"archives,omitempty"`
+
 	// AfterScript is the script to run after the backup
 	AfterScript string `json:"afterScript,omitempty"`
 
@@ -43,15 +57,256 @@ type BackupSpec struct {
 	// EncodeWith defines the encoding to use
 	EncodeWith *Encode `json:"encodeWith,omitempty"`
 
-	// Schedule defines when the backup should run
+	// RunPolicy controls retries and deadlines of the Backup's Jobs
+	// +optional
+	RunPolicy *RunPolicy `json:"runPolicy,omitempty"`
+
+	// JobTemplate customizes the pods of the Backup's Jobs, e.g. with
+	// resources, a nodeSelector or tolerations
+	// +optional
+	JobTemplate *BackupJobTemplate `json:"jobTemplate,omitempty"`
+
+	// Workspace is a volume gobackup writes dumps and archives to, instead
+	// of the node's ephemeral storage
+	// +optional
+	Workspace *BackupWorkspace `json:"workspace,omitempty"`
+
+	// DependsOn lists Backups, in the same namespace, that must have finished
+	// shortly before a run of this Backup may start. Runs wait for them in
+	// the Waiting phase.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MaxItems=20
+	DependsOn []BackupDependency `json:"dependsOn,omitempty"`
+
+	// DependencyTimeout is how long a run waits for DependsOn before it fails.
+	// Default: 1h
+	// +optional
+	DependencyTimeout *metav1.Duration `json:"dependencyTimeout,omitempty"`
+
+	// Priority orders this Backup's runs in the operator's backup queue, when
+	// concurrency limits are configured. Higher runs first; equal priorities
+	// run in the order they were queued. Default: 0
+	// +optional
+	Priority *int32 `json:"priority,omitempty"`
+
+	// RunHistoryLimit is the number of finished BackupRuns to keep for this
+	// Backup. Older runs are deleted. Unlimited when unset.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	RunHistoryLimit *int32 `json:"runHistoryLimit,omitempty"`
+
+	// RPO is the recovery point objective: the longest the Backup may go
+	// without a successful run. Once exceeded, the Stale condition is set and
+	// a Warning Event is emitted. Unmonitored when unset.
+	// +optional
+	RPO *metav1.Duration `json:"rpo,omitempty"`
+
+	// DeletionPolicy controls what is cleaned up when the Backup is deleted.
+	// Retain leaves Jobs orphaned by replaced CronJobs and all stored archives.
+	// DeleteRuntime also deletes every Job of the Backup.
+	// DeleteArtifacts also deletes the Backup's archives from every referenced
+	// Storage; gcs, azure and upyun storages are not supported.
+	// Default: Retain
+	// +optional
+	// +kubebuilder:validation:Enum=Retain;DeleteRuntime;DeleteArtifacts
+	// +kubebuilder:default=Retain
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// RunOnChange controls whether editing a scheduled Backup starts a run
+	// right away, instead of waiting for the next scheduled one.
+	// Never only applies the edit. Always runs after every spec edit.
+	// IfConfigChanged runs only when the rendered gobackup configuration
+	// changed, e.g. not for a new schedule or priority.
+	// Default: Never
+	// +optional
+	// +kubebuilder:validation:Enum=Never;Always;IfConfigChanged
+	// +kubebuilder:default=Never
+	RunOnChange RunOnChangePolicy `json:"runOnChange,omitempty"`
+
+	// Schedule defines when the backup should run.
+	// When omitted, the backup runs once right after creation; set the
+	// gobackup.io/trigger annotation to a new value to run it again.
 	Schedule *BackupSchedule `json:"schedule,omitempty"`
+
+	// Schedules defines schedule tiers, e.g. hourly, daily and monthly backups
+	// of the same databases, each with its own CronJob and retention.
+	// Mutually exclusive with Schedule.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MaxItems=10
+	Schedules []BackupScheduleTier `json:"schedules,omitempty"`
+}
+
+// BackupJobTemplate is an overlay onto the pod template the operator
+// generates for backup Jobs
+type BackupJobTemplate struct {
+	// Metadata holds labels and annotations set on the Backup's CronJobs,
+	// Jobs and pods, e.g. for cost allocation. Labels and annotations of the
+	// operator take precedence.
+	// +optional
+	Metadata JobTemplateMetadata `json:"metadata,omitempty"`
+
+	// Spec is merged into the generated PodSpec like a strategic merge
+	// patch: maps are merged, and lists such as containers and volumes are
+	// merged by name. The backup container is named gobackup.
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	Spec *runtime.RawExtension `json:"spec,omitempty"`
+}
+
+// BackupArchive selects files of a PersistentVolumeClaim to archive. The
+// claim is mounted read-only at /archives/<claimName>.
+type BackupArchive struct {
+	// ClaimName is the name of the PersistentVolumeClaim
+	ClaimName string `json:"claimName"`
+
+	// Includes are paths, relative to the root of the claim, to archive.
+	// Default: the whole claim
+	// +optional
+	Includes []string `json:"includes,omitempty"`
+
+	// Excludes are paths or globs, relative to the root of the claim, left
+	// out of the archive
+	// +optional
+	Excludes []string `json:"excludes,omitempty"`
+}
+
+// BackupWorkspace is the scratch volume of backup Jobs, mounted at
+// /workspace and used as TMPDIR
+// +kubebuilder:validation:XValidation:rule="has(self.ephemeral) != has(self.claimName)",message="set exactly one of ephemeral and claimName"
+type BackupWorkspace struct {
+	// Ephemeral provisions a PersistentVolumeClaim for every run, deleted
+	// with the run's pod
+	// +optional
+	Ephemeral *EphemeralWorkspace `json:"ephemeral,omitempty"`
+
+	// ClaimName mounts an existing PersistentVolumeClaim
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	ClaimName string `json:"claimName,omitempty"`
+}
+
+// EphemeralWorkspace is a generic ephemeral volume for the workspace
+type EphemeralWorkspace struct {
+	// StorageClassName of the claim. Default: the cluster's default class
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// Size of the claim. Default: estimated from the size of the last
+	// archive, see status.workspaceSize
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+}
+
+// JobTemplateMetadata holds the labels and annotations of a BackupJobTemplate
+type JobTemplateMetadata struct {
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// RunPolicy controls retries and deadlines of backup Jobs
+type RunPolicy struct {
+	// BackoffLimit is the number of retries before a run fails.
+	// Default: 6, the Kubernetes default
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+
+	// ActiveDeadlineSeconds bounds how long a run's Job may be active before
+	// it fails with DeadlineExceeded. With retryDelay, every attempt has its
+	// own Job and deadline.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+
+	// RetryDelay is how long to wait before retrying a failed attempt. When
+	// set, the operator retries failed runs in a new Job instead of letting
+	// Kubernetes retry pods with its exponential backoff. Runs failed by a
+	// podFailurePolicy FailJob rule are not retried.
+	// +optional
+	RetryDelay *metav1.Duration `json:"retryDelay,omitempty"`
+
+	// TTLSecondsAfterFinished is how long finished Jobs are kept.
+	// Default: jobDefaults.ttlSecondsAfterFinished of the OperatorConfig
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+
+	// PodFailurePolicy is set on the Job as is, e.g. to fail a run at once on
+	// an exit code that retrying cannot fix
+	// +optional
+	PodFailurePolicy *batchv1.PodFailurePolicy `json:"podFailurePolicy,omitempty"`
+}
+
+// DependencyOutcome is the outcome a Backup dependency must have
+type DependencyOutcome string
+
+const (
+	// DependencyOutcomeSucceeded requires a successful run
+	DependencyOutcomeSucceeded DependencyOutcome = "Succeeded"
+	// DependencyOutcomeCompleted requires a finished run, successful or not
+	DependencyOutcomeCompleted DependencyOutcome = "Completed"
+)
+
+// BackupDependency is a Backup that must have finished before a run starts
+type BackupDependency struct {
+	// Name is the name of the Backup
+	Name string `json:"name"`
+
+	// Outcome is the outcome the Backup's run must have.
+	// Default: Succeeded
+	// +optional
+	// +kubebuilder:validation:Enum=Succeeded;Completed
+	Outcome DependencyOutcome `json:"outcome,omitempty"`
+
+	// Within is how long before a run was started the Backup's run may have
+	// finished and still count, e.g. 30m. Default: 1h
+	// +optional
+	Within *metav1.Duration `json:"within,omitempty"`
+}
+
+// BackupScheduleTier is one schedule of a Backup with several tiers
+type BackupScheduleTier struct {
+	// Name identifies the tier. It is part of the CronJob and gobackup model names.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=20
+	Name string `json:"name"`
+
+	BackupSchedule `json:",inline"`
+
+	// StorageRefs limits the tier to the named entries of spec.storageRefs.
+	// Default: all of them
+	// +optional
+	StorageRefs []string `json:"storageRefs,omitempty"`
+
+	// Keep overrides the keep value of the tier's storages
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Keep *int `json:"keep,omitempty"`
 }
 
 // BackupSchedule defines the schedule for the backup
 type BackupSchedule struct {
-	// The cron expression defining the schedule
+	// The cron expression defining the schedule. Five standard fields or one of
+	// the macros @yearly, @annually, @monthly, @weekly, @daily, @midnight, @hourly.
+	// A field may use H (hash) to spread Backups sharing an expression: H picks
+	// a stable value from the Backup's namespace/name, H(a-b) one within a-b,
+	// and H/n a stable offset for every n-th value, e.g. "H H(1-4) * * *".
 	Cron string `json:"cron,omitempty"`
 
+	// TimeZone is the IANA time zone the cron expression is evaluated in,
+	// e.g. Europe/Berlin. Default: the time zone of kube-controller-manager, usually UTC
+	// +optional
+	TimeZone *string `json:"timeZone,omitempty"`
+
 	// Optional deadline in seconds for starting the job if it misses scheduled time for any reason
 	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
 
@@ -65,6 +320,7 @@ type BackupSchedule struct {
 	FailedJobsHistoryLimit *int32 `json:"failedJobsHistoryLimit,omitempty"`
 }
 
+// +kubebuilder:validation:XValidation:rule="!(has(self.keep) && has(self.retention))",message="keep and retention are mutually exclusive"
 type StorageRef struct {
 	APIGroup string `json:"apiGroup,omitempty"`
 	// Type is the storage backend type (s3, gcs, azure, local, ftp, etc.) matching the Storage resource's spec.type field
@@ -72,6 +328,50 @@ type StorageRef struct {
 	Name    string `json:"name,omitempty"`
 	Keep    int    `json:"keep,omitempty"`
 	Timeout int    `json:"timeout,omitempty"`
+
+	// Retention prunes the Backup's archives in this storage after each
+	// successful run. It replaces gobackup's count based keep, which is then
+	// not passed to gobackup, including the keep of the Storage and of
+	// schedule tiers. Supported for local, S3 compatible, sftp, scp, ftp and
+	// webdav storages.
+	// +optional
+	Retention *RetentionPolicy `json:"retention,omitempty"`
+}
+
+// RetentionPolicy selects the archives to keep, grandfather-father-son style.
+// An archive is kept when any keep rule selects it and it is not older than
+// maxAge. Days, weeks, months and years are counted in UTC.
+// +kubebuilder:validation:XValidation:rule="has(self.keepLast) || has(self.keepDaily) || has(self.keepWeekly) || has(self.keepMonthly) || has(self.keepYearly) || has(self.maxAge)",message="retention needs at least one rule"
+type RetentionPolicy struct {
+	// KeepLast keeps the most recent archives
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	KeepLast *int32 `json:"keepLast,omitempty"`
+
+	// KeepDaily keeps the most recent archive of each of the last days with an archive
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	KeepDaily *int32 `json:"keepDaily,omitempty"`
+
+	// KeepWeekly keeps the most recent archive of each of the last ISO weeks with an archive
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	KeepWeekly *int32 `json:"keepWeekly,omitempty"`
+
+	// KeepMonthly keeps the most recent archive of each of the last months with an archive
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	KeepMonthly *int32 `json:"keepMonthly,omitempty"`
+
+	// KeepYearly keeps the most recent archive of each of the last years with an archive
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	KeepYearly *int32 `json:"keepYearly,omitempty"`
+
+	// MaxAge deletes archives older than this duration (e.g. 2160h), even if
+	// a keep rule selects them. Alone, it keeps every younger archive.
+	// +optional
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
 }
 
 type DatabaseRef struct {
@@ -81,6 +381,30 @@ type DatabaseRef struct {
 	Name string `json:"name,omitempty"`
 }
 
+// DeletionPolicy controls the cleanup performed when a Backup is deleted
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyRetain only removes the objects owned by the Backup
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+	// DeletionPolicyDeleteRuntime also removes every Job run for the Backup
+	DeletionPolicyDeleteRuntime DeletionPolicy = "DeleteRuntime"
+	// DeletionPolicyDeleteArtifacts also removes the Backup's archives from its Storages
+	DeletionPolicyDeleteArtifacts DeletionPolicy = "DeleteArtifacts"
+)
+
+// RunOnChangePolicy controls the run started when a scheduled Backup is edited
+type RunOnChangePolicy string
+
+const (
+	// RunOnChangeNever waits for the next scheduled run
+	RunOnChangeNever RunOnChangePolicy = "Never"
+	// RunOnChangeAlways runs after every spec edit
+	RunOnChangeAlways RunOnChangePolicy = "Always"
+	// RunOnChangeIfConfigChanged runs when the gobackup configuration changed
+	RunOnChangeIfConfigChanged RunOnChangePolicy = "IfConfigChanged"
+)
+
 type Compress struct {
 	Type string `json:"type,omitempty"`
 }
@@ -100,17 +424,173 @@ type BackupRunStatus struct {
 	// CompletionTime is when the backup job completed
 	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
 
-	// Phase is the current phase of the backup (Pending, Running, Succeeded, Failed)
+	// Phase is the current phase of the backup (Waiting, Queued, Pending, Running, Retrying, Succeeded, Failed).
+	// Waiting runs wait for spec.dependsOn; queued runs wait for the
+	// operator's backup queue to admit them; retrying runs wait for
+	// spec.runPolicy.retryDelay after a failed attempt.
 	Phase string `json:"phase,omitempty"`
 
 	// Message contains a human-readable message indicating details about the backup
 	// This is truncated to avoid status size issues (max 1024 characters)
 	Message string `json:"message,omitempty"`
 
+	// Reason classifies why a run failed or is stuck: OOMKilled,
+	// DeadlineExceeded, ImagePullBackOff, NonZeroExit, PodFailurePolicy or Unknown
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// ExitCode is the exit code of the failed gobackup container
+	// +optional
+	ExitCode *int32 `json:"exitCode,omitempty"`
+
+	// Attempts is the number of Jobs started for the run, when the operator
+	// retries it (spec.runPolicy.retryDelay)
+	// +optional
+	Attempts int32 `json:"attempts,omitempty"`
+
 	// Logs contains the last N lines of gobackup output (truncated to avoid large status)
-	// Only captured on failure to help debugging. Max 4096 characters.
+	// In Backup status it is only captured on failure to help debugging; BackupRuns
+	// keep it for every finished run. Max 4096 characters.
 	// +optional
 	Logs string `json:"logs,omitempty"`
+
+	// Artifact describes the archive produced by a successful run
+	// +optional
+	Artifact *BackupArtifactInfo `json:"artifact,omitempty"`
+}
+
+// BackupTierStatus is the observed state of one schedule tier
+type BackupTierStatus struct {
+	// Name is the name of the tier
+	Name string `json:"name"`
+
+	// EffectiveSchedule is the tier's cron expression with H tokens resolved
+	// +optional
+	EffectiveSchedule string `json:"effectiveSchedule,omitempty"`
+
+	// NextScheduledTime is when the tier's schedule fires next
+	// +optional
+	NextScheduledTime *metav1.Time `json:"nextScheduledTime,omitempty"`
+
+	// LastSuccessfulBackupTime is when the last successful run of the tier completed
+	// +optional
+	LastSuccessfulBackupTime *metav1.Time `json:"lastSuccessfulBackupTime,omitempty"`
+
+	// LastRun contains the status of the tier's most recent run
+	// +optional
+	LastRun *BackupRunStatus `json:"lastRun,omitempty"`
+}
+
+// StorageRetentionStatus records what the retention policy of a storage kept
+// and deleted when it was last enforced
+type StorageRetentionStatus struct {
+	// Storage is the name of the storage
+	Storage string `json:"storage"`
+
+	// LastPruneTime is when the retention policy was last enforced
+	// +optional
+	LastPruneTime *metav1.Time `json:"lastPruneTime,omitempty"`
+
+	// Kept lists the archives kept by the last prune, newest first
+	// +optional
+	Kept []string `json:"kept,omitempty"`
+
+	// Deleted lists the archives deleted by the last prune, newest first
+	// +optional
+	Deleted []string `json:"deleted,omitempty"`
+
+	// Message describes why the last prune failed
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// BackupArtifactInfo describes the archive a backup run uploaded
+type BackupArtifactInfo struct {
+	// Filename is the archive file name gobackup reported in its output
+	// +optional
+	Filename string `json:"filename,omitempty"`
+
+	// Storages lists the Storage resources the archive was uploaded to
+	// +optional
+	Storages []string `json:"storages,omitempty"`
+}
+
+// ArchiveClaimStatus reports a claim of spec.archives
+type ArchiveClaimStatus struct {
+	// ClaimName is the name of the PersistentVolumeClaim
+	ClaimName string `json:"claimName"`
+
+	// ReadWriteOnce is true when the claim can only be mounted on one node
+	// +optional
+	ReadWriteOnce bool `json:"readWriteOnce,omitempty"`
+
+	// MountedBy holds the labels of a pod using a ReadWriteOnce claim.
+	// Backup Jobs are scheduled next to such pods.
+	// +optional
+	MountedBy map[string]string `json:"mountedBy,omitempty"`
+
+	// Message explains why the claim cannot be archived, if so
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// RdbVolumeStatus reports the volume holding the RDB file of a Redis
+// Database in copy mode
+type RdbVolumeStatus struct {
+	// Database is the name of the Database
+	Database string `json:"database"`
+
+	// ClaimName is the PersistentVolumeClaim holding the RDB file
+	// +optional
+	ClaimName string `json:"claimName,omitempty"`
+
+	// SubPath is the directory of the RDB file within the claim
+	// +optional
+	SubPath string `json:"subPath,omitempty"`
+
+	// MountPath is where backup Jobs mount the claim: the directory of
+	// rdb_path
+	// +optional
+	MountPath string `json:"mountPath,omitempty"`
+
+	// ReadWriteOnce is true when the claim can only be mounted on one node
+	// +optional
+	ReadWriteOnce bool `json:"readWriteOnce,omitempty"`
+
+	// MountedBy holds the labels of the Redis pod using a ReadWriteOnce
+	// claim. Backup Jobs are scheduled next to it.
+	// +optional
+	MountedBy map[string]string `json:"mountedBy,omitempty"`
+
+	// Message explains why the RDB file cannot be mounted, if so
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ExecDatabaseStatus is an exec-mode Database of a Backup, as run by the
+// init containers of its Jobs
+type ExecDatabaseStatus struct {
+	// Database is the name of the Database
+	Database string `json:"database"`
+
+	// Selector matches the database pods, in label selector syntax
+	Selector string `json:"selector"`
+
+	// Container runs the command
+	// +optional
+	Container string `json:"container,omitempty"`
+
+	// Command writes the dump to stdout
+	Command []string `json:"command"`
+
+	// Pods are the pods matching the selector when last reconciled. The
+	// Jobs of the Backup may only exec into these pods.
+	// +optional
+	Pods []string `json:"pods,omitempty"`
+
+	// Message explains why the Database is not dumped
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // BackupStatus defines the observed state of Backup
@@ -121,7 +601,7 @@ type BackupStatus struct {
 	// LastSuccessfulBackupTime is the timestamp of the last successful backup
 	LastSuccessfulBackupTime *metav1.Time `json:"lastSuccessfulBackupTime,omitempty"`
 
-	// Phase is the current phase of the backup (Idle, Running, Succeeded, Failed)
+	// Phase is the current phase of the backup (Idle, Waiting, Queued, Pending, Running, Succeeded, Failed)
 	Phase string `json:"phase,omitempty"`
 
 	// Conditions represent the latest available observations of the backup's state
@@ -148,9 +628,66 @@ type BackupStatus struct {
 
 	// ObservedGeneration is the most recent Backup spec generation that the
 	// controller has reconciled. It is used to detect manifest edits so the
-	// CronJob can be deleted and recreated only when the spec actually changes.
+	// CronJob is only updated when the spec actually changes.
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// ConfigHash is the hash of the gobackup configuration rendered for
+	// ObservedGeneration, compared by runOnChange: IfConfigChanged
+	// +optional
+	ConfigHash string `json:"configHash,omitempty"`
+
+	// NextScheduledTime is when the schedule fires next. With several schedule
+	// tiers, the earliest of them.
+	// +optional
+	NextScheduledTime *metav1.Time `json:"nextScheduledTime,omitempty"`
+
+	// Retention reports the last prune of each storage with a retention policy
+	// +optional
+	// +listType=map
+	// +listMapKey=storage
+	Retention []StorageRetentionStatus `json:"retention,omitempty"`
+
+	// EffectiveSchedule is the cron expression of spec.schedule with H
+	// tokens resolved, as used by the CronJob
+	// +optional
+	EffectiveSchedule string `json:"effectiveSchedule,omitempty"`
+
+	// Tiers reports the runs of each entry of spec.schedules
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Tiers []BackupTierStatus `json:"tiers,omitempty"`
+
+	// Archives reports the claims of spec.archives
+	// +optional
+	// +listType=map
+	// +listMapKey=claimName
+	Archives []ArchiveClaimStatus `json:"archives,omitempty"`
+
+	// RdbVolumes reports the volumes holding the RDB files of Redis
+	// Databases with config.rdb_source
+	// +optional
+	// +listType=map
+	// +listMapKey=database
+	RdbVolumes []RdbVolumeStatus `json:"rdbVolumes,omitempty"`
+
+	// ExecDatabases lists the Databases dumped through pods/exec
+	// +optional
+	// +listType=map
+	// +listMapKey=database
+	ExecDatabases []ExecDatabaseStatus `json:"execDatabases,omitempty"`
+
+	// WorkspaceSize is the size requested for an ephemeral workspace without
+	// a size: three times the last archive, rounded up to a power of two
+	// GiB, or 10Gi before the first archive
+	// +optional
+	WorkspaceSize *resource.Quantity `json:"workspaceSize,omitempty"`
+
+	// ObservedTrigger is the last gobackup.io/trigger annotation value that
+	// started a run of an unscheduled Backup.
+	// +optional
+	ObservedTrigger string `json:"observedTrigger,omitempty"`
 }
 
 //+kubebuilder:resource:shortName=backup
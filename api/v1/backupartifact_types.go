@@ -0,0 +1,77 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BackupArtifactSpec describes an archive found in a Storage. BackupArtifacts
+// are maintained by the operator from periodic listings of the Storage path.
+type BackupArtifactSpec struct {
+	// StorageRef references the Storage holding the archive
+	StorageRef corev1.LocalObjectReference `json:"storageRef"`
+
+	// BackupRef references the Backup that created the archive, when known
+	// +optional
+	BackupRef *corev1.LocalObjectReference `json:"backupRef,omitempty"`
+
+	// Key is the object name of the archive relative to the Storage path
+	Key string `json:"key"`
+
+	// Size is the size of the archive in bytes
+	// +optional
+	Size int64 `json:"size,omitempty"`
+
+	// Timestamp is when the archive was written to the Storage
+	// +optional
+	Timestamp *metav1.Time `json:"timestamp,omitempty"`
+
+	// Checksum is "<algorithm>:<digest>" when the Storage reports one, e.g. md5:<hex> for S3 ETags
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
+}
+
+//+kubebuilder:resource:shortName=artifact
+//+kubebuilder:object:root=true
+//+kubebuilder:printcolumn:name="Storage",type=string,JSONPath=`.spec.storageRef.name`
+//+kubebuilder:printcolumn:name="Backup",type=string,JSONPath=`.spec.backupRef.name`
+//+kubebuilder:printcolumn:name="Key",type=string,JSONPath=`.spec.key`
+//+kubebuilder:printcolumn:name="Size",type=integer,JSONPath=`.spec.size`
+//+kubebuilder:printcolumn:name="Timestamp",type=date,JSONPath=`.spec.timestamp`
+
+// BackupArtifact is the Schema for the backupartifacts API
+type BackupArtifact struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec BackupArtifactSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// BackupArtifactList contains a list of BackupArtifact
+type BackupArtifactList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BackupArtifact `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BackupArtifact{}, &BackupArtifactList{})
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BackupRunSpec defines the desired state of BackupRun
+type BackupRunSpec struct {
+	// BackupRef references the Backup, in the same namespace, to run
+	BackupRef corev1.LocalObjectReference `json:"backupRef"`
+
+	// Tier runs only the named entry of the Backup's spec.schedules.
+	// Default: every tier
+	// +optional
+	Tier string `json:"tier,omitempty"`
+}
+
+//+kubebuilder:resource:shortName=backuprun
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Backup",type=string,JSONPath=`.spec.backupRef.name`
+//+kubebuilder:printcolumn:name="Tier",type=string,JSONPath=`.spec.tier`,priority=1
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.reason`,priority=1
+//+kubebuilder:printcolumn:name="Started",type=date,JSONPath=`.status.startTime`
+//+kubebuilder:printcolumn:name="Completed",type=date,JSONPath=`.status.completionTime`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// BackupRun is the Schema for the backupruns API.
+// Each BackupRun represents a single execution of a Backup. Creating one starts
+// an on-demand run; runs started by the Backup's CronJob are recorded as
+// BackupRuns as well.
+type BackupRun struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BackupRunSpec   `json:"spec,omitempty"`
+	Status BackupRunStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// BackupRunList contains a list of BackupRun
+type BackupRunList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BackupRun `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BackupRun{}, &BackupRunList{})
+}
@@ -19,6 +19,7 @@ package v1
 import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DatabaseSpec defines the desired state of Database
@@ -28,6 +29,8 @@ import (
 // +kubebuilder:validation:XValidation:rule="self.type == 'redis' || !has(self.config.invoke_save)",message="config.invoke_save is only valid when spec.type is redis"
 // +kubebuilder:validation:XValidation:rule="self.type == 'redis' || !has(self.config.rdb_path)",message="config.rdb_path is only valid when spec.type is redis"
 // +kubebuilder:validation:XValidation:rule="self.type == 'redis' || !has(self.config.args_redis)",message="config.args_redis is only valid when spec.type is redis"
+// +kubebuilder:validation:XValidation:rule="self.type == 'redis' || !has(self.config.rdb_source)",message="config.rdb_source is only valid when spec.type is redis"
+// +kubebuilder:validation:XValidation:rule="!has(self.config.rdb_source) || !has(self.config.mode) || self.config.mode == 'copy'",message="config.rdb_source is only valid in copy mode"
 // +kubebuilder:validation:XValidation:rule="self.type == 'mongodb' || !has(self.config.auth_db)",message="config.auth_db is only valid when spec.type is mongodb"
 // +kubebuilder:validation:XValidation:rule="self.type == 'mongodb' || !has(self.config.oplog)",message="config.oplog is only valid when spec.type is mongodb"
 // +kubebuilder:validation:XValidation:rule="self.type == 'mssql' || !has(self.config.trust_server_certificate)",message="config.trust_server_certificate is only valid when spec.type is mssql"
@@ -37,7 +40,10 @@ import (
 // +kubebuilder:validation:XValidation:rule="self.type == 'influxdb' || !has(self.config.org)",message="config.org is only valid when spec.type is influxdb"
 // +kubebuilder:validation:XValidation:rule="self.type == 'etcd' || !has(self.config.endpoints)",message="config.endpoints is only valid when spec.type is etcd"
 // +kubebuilder:validation:XValidation:rule="self.type in ['postgresql', 'mysql', 'mariadb', 'mssql'] || !has(self.config.tables)",message="config.tables is only valid for SQL databases (postgresql, mysql, mariadb, mssql)"
+// +kubebuilder:validation:XValidation:rule="!has(self.config.serviceRef) || (!has(self.config.host) && !has(self.config.port) && !has(self.config.socket))",message="config.serviceRef replaces config.host, config.port and config.socket"
+// +kubebuilder:validation:XValidation:rule="!has(self.config.serviceRef) || !has(self.config.fromObject)",message="set at most one of config.serviceRef and config.fromObject"
 // +kubebuilder:validation:XValidation:rule="self.type in ['postgresql', 'mysql', 'mariadb', 'mssql'] || !has(self.config.exclude_tables)",message="config.exclude_tables is only valid for SQL databases (postgresql, mysql, mariadb, mssql)"
+// +kubebuilder:validation:XValidation:rule="!has(self.exec) || has(self.exec.command) || self.type in ['postgresql', 'mysql', 'mariadb', 'mongodb', 'redis']",message="exec.command is required for this database type"
 type DatabaseSpec struct {
 	// Type is the database backend type
 	// +kubebuilder:validation:Enum=postgresql;mysql;mariadb;mongodb;redis;mssql;influxdb;etcd
@@ -45,6 +51,32 @@ type DatabaseSpec struct {
 
 	// Config contains the database configuration
 	Config DatabaseConfig `json:"config"`
+
+	// Exec dumps the database by running a command in its pod through
+	// pods/exec, for databases that only listen on localhost or a socket.
+	// The dump is archived instead of being taken by gobackup over the
+	// network.
+	// +optional
+	Exec *DatabaseExec `json:"exec,omitempty"`
+}
+
+// DatabaseExec selects the pod and command of an exec-mode dump
+type DatabaseExec struct {
+	// Selector matches the database pods; the first running one by name is
+	// used
+	Selector metav1.LabelSelector `json:"selector"`
+
+	// Container runs the command. Default: the default container of the pod
+	// +optional
+	Container string `json:"container,omitempty"`
+
+	// Command writes the dump to stdout. Default, by type: pg_dump as
+	// $POSTGRES_USER, mysqldump or mariadb-dump as root with
+	// $MYSQL_ROOT_PASSWORD or $MARIADB_ROOT_PASSWORD, mongodump --archive
+	// and redis-cli --rdb -, with config.username and config.database when
+	// set
+	// +optional
+	Command []string `json:"command,omitempty"`
 }
 
 // DatabaseConfig defines the configuration for all database types
@@ -59,6 +91,16 @@ type DatabaseConfig struct {
 	// Default for PostgreSQL: 5432, for Redis: 6379
 	Port *int `json:"port,omitempty"`
 
+	// ServiceRef resolves host and port from a Service when the Secret of a
+	// Backup is rendered, instead of hardcoding them
+	ServiceRef *DatabaseServiceRef `json:"serviceRef,omitempty"`
+
+	// FromObject reads host, port, username, password or database from an
+	// object in the namespace of the Database, such as the custom resource
+	// or Secret another operator publishes them in. Mapped fields override
+	// those set here.
+	FromObject *DatabaseObjectRef `json:"fromObject,omitempty"`
+
 	// Socket is the database server socket
 	// For PostgreSQL: e.g. /var/run/postgresql/.s.PGSQL.5432
 	// For Redis: e.g. /var/run/redis/redis.sock
@@ -147,17 +189,124 @@ type DatabaseConfig struct {
 
 	// ArgsRedis are additional options for redis-cli utility, for example: --tls --cacert redis_ca.pem
 	ArgsRedis *string `json:"args_redis,omitempty"`
+
+	// RdbSource locates the volume holding rdb_path in copy mode (Redis).
+	// Backup Jobs mount it read-only at the directory of rdb_path, on the
+	// node of the Redis pod when the volume is ReadWriteOnce.
+	RdbSource *RedisRdbSource `json:"rdb_source,omitempty"`
+}
+
+// RedisRdbSource is the PersistentVolumeClaim, or the pod, holding the RDB
+// file of a Redis server
+// +kubebuilder:validation:XValidation:rule="has(self.claim_name) != has(self.pod_name)",message="set exactly one of claim_name and pod_name"
+// +kubebuilder:validation:XValidation:rule="!has(self.sub_path) || has(self.claim_name)",message="sub_path is only valid with claim_name"
+type RedisRdbSource struct {
+	// ClaimName is the PersistentVolumeClaim holding the directory of
+	// rdb_path
+	ClaimName *string `json:"claim_name,omitempty"`
+
+	// SubPath is the directory of rdb_path within the claim. Default: the
+	// root of the claim
+	SubPath *string `json:"sub_path,omitempty"`
+
+	// PodName is the Redis pod, such as redis-0 of a StatefulSet. The claim
+	// it mounts at or above the directory of rdb_path is used.
+	PodName *string `json:"pod_name,omitempty"`
+}
+
+// DatabaseServiceRef selects a port of a Service and, optionally, a replica
+// behind it
+type DatabaseServiceRef struct {
+	// Name is the name of the Service
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the Service. Default: the namespace of
+	// the Database
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Port is the name or number of a port of the Service. Default: its
+	// only port
+	// +optional
+	Port *intstr.IntOrString `json:"port,omitempty"`
+
+	// ReplicaSelector picks, by pod name, the first ready endpoint of the
+	// Service whose pod matches, such as role=replica, to keep dumps off the
+	// primary. The Service itself is used while no such endpoint is ready.
+	// +optional
+	ReplicaSelector *metav1.LabelSelector `json:"replicaSelector,omitempty"`
+}
+
+// DatabaseObjectRef maps fields of an object to the connection settings of a
+// Database
+type DatabaseObjectRef struct {
+	// APIVersion of the object, such as postgresql.cnpg.io/v1 or v1
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the object
+	Kind string `json:"kind"`
+
+	// Name of the object
+	Name string `json:"name"`
+
+	// FieldPaths maps host, port, username, password and database to
+	// JSONPath expressions evaluated against the object, such as
+	// {.status.writeService}. Values under .data of a Secret are decoded.
+	// +kubebuilder:validation:MinProperties=1
+	// +kubebuilder:validation:XValidation:rule="self.all(k, k in ['host', 'port', 'username', 'password', 'database'])",message="fieldPaths keys must be host, port, username, password or database"
+	FieldPaths map[string]string `json:"fieldPaths"`
+}
+
+// ServiceTarget is the host and port config.serviceRef resolved to
+type ServiceTarget struct {
+	// Host is the DNS name of the Service or of the replica, or the IP of
+	// a replica without a hostname
+	Host string `json:"host"`
+
+	// Port is the port dumps connect to
+	Port int32 `json:"port"`
+
+	// Pod is the replica picked by replicaSelector, if any
+	// +optional
+	Pod string `json:"pod,omitempty"`
+
+	// Message explains why the Service is used despite replicaSelector
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // DatabaseStatus defines the observed state of Database
 type DatabaseStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	// ServiceTarget is where config.serviceRef pointed when the Database
+	// was last checked
+	// +optional
+	ServiceTarget *ServiceTarget `json:"serviceTarget,omitempty"`
+
+	// ObservedGeneration is the generation of the spec last checked
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions report whether the Database can be backed up: Ready, and
+	// SecretsResolved for the Secrets its config references
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Backups are the names of the Backups referencing the Database
+	// +optional
+	Backups []string `json:"backups,omitempty"`
 }
 
 //+kubebuilder:resource:shortName=db
+//+kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Message",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].message`,priority=1
+//+kubebuilder:printcolumn:name="Backups",type=string,JSONPath=`.status.backups`,priority=1
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:storageversion
 
 // Database is the Schema for the databases API
 type Database struct {
@@ -0,0 +1,111 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OperatorConfigName is the name of the OperatorConfig the operator reads;
+// others are ignored
+const OperatorConfigName = "default"
+
+// OperatorConfigSpec holds operator wide settings
+type OperatorConfigSpec struct {
+	// JobDefaults are the defaults of every backup and restore Job
+	// +optional
+	JobDefaults JobDefaults `json:"jobDefaults,omitempty"`
+}
+
+// JobDefaults are the defaults of the Jobs the operator creates. Per-Backup
+// settings, such as spec.jobTemplate, spec.runPolicy and the history limits
+// of a schedule, take precedence.
+type JobDefaults struct {
+	// Image runs gobackup. Default: the BACKUP_JOB_IMAGE env var of the
+	// operator, or huacnlee/gobackup:latest
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// ImagePullPolicy of the gobackup container. Default: IfNotPresent
+	// +optional
+	// +kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// ImagePullSecrets of Job pods. Default: regcred. An empty list sets none.
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// TTLSecondsAfterFinished is how long finished Jobs are kept. Default: 60
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+
+	// SuccessfulJobsHistoryLimit of CronJobs. Default: 3
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	SuccessfulJobsHistoryLimit *int32 `json:"successfulJobsHistoryLimit,omitempty"`
+
+	// FailedJobsHistoryLimit of CronJobs. Default: 1
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	FailedJobsHistoryLimit *int32 `json:"failedJobsHistoryLimit,omitempty"`
+
+	// Resources of the gobackup container
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// SecurityContext of the gobackup container
+	// +optional
+	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
+
+	// PodSecurityContext of Job pods
+	// +optional
+	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+
+	// LogTailLines is the number of log lines collected from finished Jobs
+	// into run status, which is capped at 4KB. Default: 100
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	LogTailLines *int64 `json:"logTailLines,omitempty"`
+}
+
+//+kubebuilder:resource:scope=Cluster,shortName=opconfig
+//+kubebuilder:object:root=true
+//+kubebuilder:validation:XValidation:rule="self.metadata.name == 'default'",message="the operator only reads the OperatorConfig named default"
+
+// OperatorConfig is the Schema for the operatorconfigs API. The operator reads
+// the one named default, and applies changes without a restart.
+type OperatorConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec OperatorConfigSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// OperatorConfigList contains a list of OperatorConfig
+type OperatorConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OperatorConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OperatorConfig{}, &OperatorConfigList{})
+}
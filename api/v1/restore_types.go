@@ -0,0 +1,181 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RestoreSpec defines the desired state of Restore
+type RestoreSpec struct {
+	// StorageRef references the Storage, in the same namespace, holding the artifact
+	StorageRef corev1.LocalObjectReference `json:"storageRef"`
+
+	// BackupRef restricts latest and timestamp to the archives the BackupRuns
+	// of this Backup recorded, as listed in their BackupArtifacts.
+	// Default: every archive in the Storage
+	// +optional
+	BackupRef *corev1.LocalObjectReference `json:"backupRef,omitempty"`
+
+	// Tier restricts latest and timestamp to the archives of this schedule
+	// tier, which are stored in the tier's directory. Default: every tier
+	// +optional
+	Tier string `json:"tier,omitempty"`
+
+	// Artifact selects the archive to restore
+	Artifact RestoreArtifact `json:"artifact"`
+
+	// CompressWith is the compression the archive was created with. Default: tgz
+	// +optional
+	CompressWith *Compress `json:"compressWith,omitempty"`
+
+	// EncodeWith describes how the archive was encrypted. Omit for plain archives.
+	// +optional
+	EncodeWith *RestoreEncode `json:"encodeWith,omitempty"`
+
+	// DatabaseRef references the Database, in the same namespace, to restore into
+	DatabaseRef corev1.LocalObjectReference `json:"databaseRef"`
+
+	// SourceDatabase is the name of the Database entry inside the archive, i.e.
+	// the databaseRef name used by the Backup that created it.
+	// Default: the name of databaseRef
+	// +optional
+	SourceDatabase string `json:"sourceDatabase,omitempty"`
+
+	// TargetVolume is the volume holding the data directory of the target.
+	// Required for redis (the RDB file is replaced) and etcd (the snapshot is
+	// restored into a new data directory). The database must be stopped while
+	// the restore runs.
+	// +optional
+	TargetVolume *RestoreTargetVolume `json:"targetVolume,omitempty"`
+}
+
+// RestoreArtifact selects an archive in the Storage. Exactly one field must be set.
+// +kubebuilder:validation:XValidation:rule="(has(self.latest) && self.latest ? 1 : 0) + (has(self.timestamp) ? 1 : 0) + (has(self.key) ? 1 : 0) == 1",message="exactly one of latest, timestamp or key must be set"
+type RestoreArtifact struct {
+	// Latest restores the newest archive. Archives are looked up in the
+	// BackupArtifacts of the Storage, so it is not supported for storages
+	// the operator does not inventory.
+	// +optional
+	Latest bool `json:"latest,omitempty"`
+
+	// Timestamp restores the newest archive created at or before this time.
+	// Archives are looked up like for latest.
+	// +optional
+	Timestamp *metav1.Time `json:"timestamp,omitempty"`
+
+	// Key is the exact object key of the archive, relative to the Storage
+	// path, e.g. daily/2024.06.01.02.00.00.tar.gz for the daily tier
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// RestoreEncode describes the encryption of an archive
+type RestoreEncode struct {
+	// Type is the encoder used by gobackup
+	// +kubebuilder:validation:Enum=openssl
+	Type string `json:"type"`
+
+	// PasswordRef references a Secret containing the encryption password
+	PasswordRef corev1.SecretKeySelector `json:"passwordRef"`
+
+	// Cipher is the openssl cipher. Default: aes-256-cbc
+	// +optional
+	Cipher string `json:"cipher,omitempty"`
+
+	// Base64 is set when the archive was base64 encoded
+	// +optional
+	Base64 bool `json:"base64,omitempty"`
+
+	// Args are additional arguments for openssl, e.g. -pbkdf2
+	// +optional
+	Args string `json:"args,omitempty"`
+}
+
+// RestoreTargetVolume is a PersistentVolumeClaim mounted into the restore Job
+type RestoreTargetVolume struct {
+	// ClaimName is the name of the PersistentVolumeClaim
+	ClaimName string `json:"claimName"`
+
+	// Path is where the data lives inside the volume.
+	// For redis: the RDB file name, default dump.rdb.
+	// For etcd: the data directory to create, default default.etcd.
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+// RestoreStatus defines the observed state of Restore
+type RestoreStatus struct {
+	// Phase is the current phase of the restore (Pending, Running, Succeeded, Failed)
+	Phase string `json:"phase,omitempty"`
+
+	// JobName is the name of the Job that runs the restore
+	// +optional
+	JobName string `json:"jobName,omitempty"`
+
+	// Artifact is the object key of the archive that was restored
+	// +optional
+	Artifact string `json:"artifact,omitempty"`
+
+	// StartTime is when the restore job started
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is when the restore job completed
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Message contains a human-readable message about the restore (max 1024 characters)
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Logs contains the last lines of the restore output (max 4096 characters)
+	// +optional
+	Logs string `json:"logs,omitempty"`
+}
+
+//+kubebuilder:resource:shortName=restore
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Database",type=string,JSONPath=`.spec.databaseRef.name`
+//+kubebuilder:printcolumn:name="Storage",type=string,JSONPath=`.spec.storageRef.name`
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="Artifact",type=string,JSONPath=`.status.artifact`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// Restore is the Schema for the restores API
+type Restore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RestoreSpec   `json:"spec,omitempty"`
+	Status RestoreStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// RestoreList contains a list of Restore
+type RestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Restore `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Restore{}, &RestoreList{})
+}
@@ -180,11 +180,28 @@ type StorageConfig struct {
 
 // StorageStatus defines the observed state of Storage
 type StorageStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	// ObservedGeneration is the generation of the spec last checked
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions report whether backups can be uploaded to the Storage:
+	// Ready, and SecretsResolved for the Secrets its config references
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Backups are the names of the Backups referencing the Storage
+	// +optional
+	Backups []string `json:"backups,omitempty"`
 }
 
 //+kubebuilder:resource:shortName=storage
+//+kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Message",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].message`,priority=1
+//+kubebuilder:printcolumn:name="Backups",type=string,JSONPath=`.status.backups`,priority=1
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 
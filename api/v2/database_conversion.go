@@ -0,0 +1,403 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+)
+
+const (
+	// OptionsAnnotation holds, on a v1 Database, the typed options of its v2
+	// engine, which v1 only has rendered into config.args
+	OptionsAnnotation = "gobackup.io/v2-options"
+
+	// V1ConfigAnnotation holds, on a v2 Database, the fields of its v1
+	// config that its engine has no field for
+	V1ConfigAnnotation = "gobackup.io/v1-config"
+)
+
+var _ conversion.Convertible = &Database{}
+
+// ConvertTo converts this Database to the Hub version (v1)
+func (src *Database) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*backupv1.Database)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	delete(dst.Annotations, V1ConfigAnnotation)
+	dst.Status = *src.Status.DeepCopy()
+	dst.Spec = backupv1.DatabaseSpec{Exec: src.Spec.Exec.DeepCopy()}
+
+	config := &dst.Spec.Config
+	var options any
+	switch spec := src.Spec; {
+	case spec.PostgreSQL != nil:
+		e := spec.PostgreSQL.DeepCopy()
+		dst.Spec.Type = "postgresql"
+		e.Endpoint.into(config)
+		e.Credentials.into(config)
+		config.Database, config.Tables, config.ExcludeTables = e.Database, e.Tables, e.ExcludeTables
+		config.Args = e.Args()
+		options = e.PostgreSQLOptions
+	case spec.MySQL != nil, spec.MariaDB != nil:
+		e := spec.MySQL.DeepCopy()
+		dst.Spec.Type = "mysql"
+		if spec.MariaDB != nil {
+			e = spec.MariaDB.DeepCopy()
+			dst.Spec.Type = "mariadb"
+		}
+		e.Endpoint.into(config)
+		e.Credentials.into(config)
+		config.Database, config.Tables, config.ExcludeTables = e.Database, e.Tables, e.ExcludeTables
+		config.Args = e.Args()
+		options = e.MySQLOptions
+	case spec.MongoDB != nil:
+		e := spec.MongoDB.DeepCopy()
+		dst.Spec.Type = "mongodb"
+		e.Endpoint.into(config)
+		e.Credentials.into(config)
+		config.Database, config.AuthDB, config.Oplog = e.Database, e.AuthDB, e.Oplog
+		config.Args = e.Args()
+		options = e.MongoDBOptions
+	case spec.Redis != nil:
+		e := spec.Redis.DeepCopy()
+		dst.Spec.Type = "redis"
+		e.Endpoint.into(config)
+		config.Password, config.PasswordRef = e.Password, e.PasswordRef
+		config.Mode, config.InvokeSave, config.RdbPath, config.RdbSource = e.Mode, e.InvokeSave, e.RdbPath, e.RdbSource
+		config.ArgsRedis = e.ExtraArgs
+	case spec.MSSQL != nil:
+		e := spec.MSSQL.DeepCopy()
+		dst.Spec.Type = "mssql"
+		e.Endpoint.into(config)
+		e.Credentials.into(config)
+		config.Database, config.TrustServerCertificate, config.Args = e.Database, e.TrustServerCertificate, e.ExtraArgs
+	case spec.InfluxDB != nil:
+		e := spec.InfluxDB.DeepCopy()
+		dst.Spec.Type = "influxdb"
+		e.Endpoint.into(config)
+		config.Token, config.TokenRef, config.Bucket, config.Organization = e.Token, e.TokenRef, e.Bucket, e.Organization
+	case spec.ETCD != nil:
+		e := spec.ETCD.DeepCopy()
+		dst.Spec.Type = "etcd"
+		config.Endpoints = e.Endpoints
+		config.Args = e.Args()
+		options = e.ETCDOptions
+	default:
+		return fmt.Errorf("database %s sets no engine", src.Name)
+	}
+
+	delete(dst.Annotations, OptionsAnnotation)
+	if options != nil {
+		data, err := json.Marshal(options)
+		if err != nil {
+			return fmt.Errorf("failed to marshal options of database %s: %w", src.Name, err)
+		}
+		if string(data) != "{}" {
+			if dst.Annotations == nil {
+				dst.Annotations = map[string]string{}
+			}
+			dst.Annotations[OptionsAnnotation] = string(data)
+		}
+	}
+
+	if leftovers := src.Annotations[V1ConfigAnnotation]; leftovers != "" {
+		if err := mergeConfig(config, leftovers); err != nil {
+			return fmt.Errorf("failed to restore v1 config of database %s: %w", src.Name, err)
+		}
+	}
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1) to this version
+func (dst *Database) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*backupv1.Database)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	delete(dst.Annotations, OptionsAnnotation)
+	delete(dst.Annotations, V1ConfigAnnotation)
+	dst.Status = *src.Status.DeepCopy()
+	dst.Spec = DatabaseSpec{Exec: src.Spec.Exec.DeepCopy()}
+
+	c := src.Spec.Config.DeepCopy()
+	options := src.Annotations[OptionsAnnotation]
+	switch src.Spec.Type {
+	case "postgresql":
+		e := &PostgreSQL{Endpoint: endpointFrom(c), Credentials: credentialsFrom(c),
+			Database: c.Database, Tables: c.Tables, ExcludeTables: c.ExcludeTables}
+		e.ExtraArgs = splitOptions(options, &e.PostgreSQLOptions, PostgreSQLOptions.Flags, c.Args)
+		dst.Spec.PostgreSQL = e
+	case "mysql", "mariadb":
+		e := &MySQL{Endpoint: endpointFrom(c), Credentials: credentialsFrom(c),
+			Database: c.Database, Tables: c.Tables, ExcludeTables: c.ExcludeTables}
+		e.ExtraArgs = splitOptions(options, &e.MySQLOptions, MySQLOptions.Flags, c.Args)
+		if src.Spec.Type == "mariadb" {
+			dst.Spec.MariaDB = e
+		} else {
+			dst.Spec.MySQL = e
+		}
+	case "mongodb":
+		e := &MongoDB{Endpoint: endpointFrom(c), Credentials: credentialsFrom(c),
+			Database: c.Database, AuthDB: c.AuthDB, Oplog: c.Oplog}
+		e.ExtraArgs = splitOptions(options, &e.MongoDBOptions, MongoDBOptions.Flags, c.Args)
+		dst.Spec.MongoDB = e
+	case "redis":
+		dst.Spec.Redis = &Redis{Endpoint: endpointFrom(c), Password: c.Password, PasswordRef: c.PasswordRef,
+			Mode: c.Mode, InvokeSave: c.InvokeSave, RdbPath: c.RdbPath, RdbSource: c.RdbSource, ExtraArgs: c.ArgsRedis}
+	case "mssql":
+		dst.Spec.MSSQL = &MSSQL{Endpoint: endpointFrom(c), Credentials: credentialsFrom(c),
+			Database: c.Database, TrustServerCertificate: c.TrustServerCertificate, ExtraArgs: c.Args}
+	case "influxdb":
+		dst.Spec.InfluxDB = &InfluxDB{Endpoint: endpointFrom(c), Token: c.Token, TokenRef: c.TokenRef,
+			Bucket: c.Bucket, Organization: c.Organization}
+	case "etcd":
+		e := &ETCD{Endpoints: c.Endpoints}
+		e.ExtraArgs = splitOptions(options, &e.ETCDOptions, ETCDOptions.Flags, c.Args)
+		dst.Spec.ETCD = e
+	default:
+		return fmt.Errorf("database %s has unknown type %s", src.Name, src.Spec.Type)
+	}
+
+	// Keep the v1 fields the engine has no field for, so that converting
+	// back restores them
+	back := &backupv1.Database{}
+	if err := dst.ConvertTo(back); err != nil {
+		return err
+	}
+	leftovers, err := configDiff(&src.Spec.Config, &back.Spec.Config)
+	if err != nil {
+		return fmt.Errorf("failed to compare v1 config of database %s: %w", src.Name, err)
+	}
+	if leftovers != "" {
+		if dst.Annotations == nil {
+			dst.Annotations = map[string]string{}
+		}
+		dst.Annotations[V1ConfigAnnotation] = leftovers
+	}
+	return nil
+}
+
+// Args returns the typed options of the engine rendered as pg_dump
+// arguments, followed by ExtraArgs
+func (e *PostgreSQL) Args() *string {
+	return joinArgs(e.PostgreSQLOptions.Flags(), e.ExtraArgs)
+}
+
+// Args returns the typed options of the engine rendered as mysqldump
+// arguments, followed by ExtraArgs
+func (e *MySQL) Args() *string {
+	return joinArgs(e.MySQLOptions.Flags(), e.ExtraArgs)
+}
+
+// Args returns the typed options of the engine rendered as mongodump
+// arguments, followed by ExtraArgs
+func (e *MongoDB) Args() *string {
+	return joinArgs(e.MongoDBOptions.Flags(), e.ExtraArgs)
+}
+
+// Args returns the typed options of the engine rendered as etcdctl
+// arguments, followed by ExtraArgs
+func (e *ETCD) Args() *string {
+	return joinArgs(e.ETCDOptions.Flags(), e.ExtraArgs)
+}
+
+// Flags returns the pg_dump arguments of the options
+func (o PostgreSQLOptions) Flags() []string {
+	var flags []string
+	for _, schema := range o.Schemas {
+		flags = append(flags, "--schema="+schema)
+	}
+	if o.NoOwner {
+		flags = append(flags, "--no-owner")
+	}
+	if o.NoPrivileges {
+		flags = append(flags, "--no-privileges")
+	}
+	return flags
+}
+
+// Flags returns the mysqldump arguments of the options
+func (o MySQLOptions) Flags() []string {
+	var flags []string
+	if o.SingleTransaction {
+		flags = append(flags, "--single-transaction")
+	}
+	if o.Routines {
+		flags = append(flags, "--routines")
+	}
+	if o.Events {
+		flags = append(flags, "--events")
+	}
+	if o.SkipSSL {
+		flags = append(flags, "--skip-ssl")
+	}
+	return flags
+}
+
+// Flags returns the mongodump arguments of the options
+func (o MongoDBOptions) Flags() []string {
+	var flags []string
+	for _, collection := range o.ExcludeCollections {
+		flags = append(flags, "--excludeCollection="+collection)
+	}
+	if o.ReadPreference != "" {
+		flags = append(flags, "--readPreference="+o.ReadPreference)
+	}
+	return flags
+}
+
+// Flags returns the etcdctl arguments of the options
+func (o ETCDOptions) Flags() []string {
+	var flags []string
+	if o.DialTimeout != nil {
+		flags = append(flags, "--dial-timeout="+o.DialTimeout.Duration.String())
+	}
+	if o.CommandTimeout != nil {
+		flags = append(flags, "--command-timeout="+o.CommandTimeout.Duration.String())
+	}
+	return flags
+}
+
+// into sets the endpoint fields of a v1 config
+func (e Endpoint) into(config *backupv1.DatabaseConfig) {
+	config.Host, config.Port, config.Socket = e.Host, e.Port, e.Socket
+	config.ServiceRef, config.FromObject = e.ServiceRef, e.FromObject
+}
+
+// into sets the credential fields of a v1 config
+func (c Credentials) into(config *backupv1.DatabaseConfig) {
+	config.Username, config.UsernameRef = c.Username, c.UsernameRef
+	config.Password, config.PasswordRef = c.Password, c.PasswordRef
+}
+
+// endpointFrom returns the endpoint fields of a v1 config
+func endpointFrom(config *backupv1.DatabaseConfig) Endpoint {
+	return Endpoint{Host: config.Host, Port: config.Port, Socket: config.Socket,
+		ServiceRef: config.ServiceRef, FromObject: config.FromObject}
+}
+
+// credentialsFrom returns the credential fields of a v1 config
+func credentialsFrom(config *backupv1.DatabaseConfig) Credentials {
+	return Credentials{Username: config.Username, UsernameRef: config.UsernameRef,
+		Password: config.Password, PasswordRef: config.PasswordRef}
+}
+
+// joinArgs renders typed options in front of free-form arguments, or nil
+func joinArgs(flags []string, extra *string) *string {
+	if extra != nil && *extra != "" {
+		flags = append(flags, *extra)
+	}
+	if len(flags) == 0 {
+		return extra
+	}
+	args := strings.Join(flags, " ")
+	return &args
+}
+
+// splitOptions restores the options recorded in OptionsAnnotation and returns
+// the arguments that follow their flags. When args no longer start with
+// those flags, as after an edit through v1, the options are dropped and
+// args are kept whole.
+func splitOptions[T any](annotation string, options *T, flags func(T) []string, args *string) *string {
+	if annotation == "" {
+		return args
+	}
+	if err := json.Unmarshal([]byte(annotation), options); err != nil {
+		*options = *new(T)
+		return args
+	}
+	prefix := strings.Join(flags(*options), " ")
+	switch {
+	case prefix == "":
+		return args
+	case args != nil && *args == prefix:
+		return nil
+	case args != nil && strings.HasPrefix(*args, prefix+" "):
+		rest := strings.TrimPrefix(*args, prefix+" ")
+		return &rest
+	}
+	*options = *new(T)
+	return args
+}
+
+// configDiff returns, as JSON, the fields of orig that differ in back, with
+// null for those only back sets, or ""
+func configDiff(orig, back *backupv1.DatabaseConfig) (string, error) {
+	origMap, err := toMap(orig)
+	if err != nil {
+		return "", err
+	}
+	backMap, err := toMap(back)
+	if err != nil {
+		return "", err
+	}
+	diff := map[string]any{}
+	for key, value := range origMap {
+		if !reflect.DeepEqual(value, backMap[key]) {
+			diff[key] = value
+		}
+	}
+	for key := range backMap {
+		if _, ok := origMap[key]; !ok {
+			diff[key] = nil
+		}
+	}
+	if len(diff) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(diff)
+	return string(data), err
+}
+
+// mergeConfig applies fields recorded by configDiff to a v1 config
+func mergeConfig(config *backupv1.DatabaseConfig, leftovers string) error {
+	configMap, err := toMap(config)
+	if err != nil {
+		return err
+	}
+	diff := map[string]any{}
+	if err := json.Unmarshal([]byte(leftovers), &diff); err != nil {
+		return err
+	}
+	for key, value := range diff {
+		if value == nil {
+			delete(configMap, key)
+		} else {
+			configMap[key] = value
+		}
+	}
+	data, err := json.Marshal(configMap)
+	if err != nil {
+		return err
+	}
+	*config = backupv1.DatabaseConfig{}
+	return json.Unmarshal(data, config)
+}
+
+// toMap returns a v1 config as JSON fields
+func toMap(config *backupv1.DatabaseConfig) (map[string]any, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	configMap := map[string]any{}
+	return configMap, json.Unmarshal(data, &configMap)
+}
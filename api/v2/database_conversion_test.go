@@ -0,0 +1,289 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+)
+
+// ptr returns a pointer to value
+func ptr[T any](value T) *T {
+	return &value
+}
+
+func TestDatabaseConvertTo(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    DatabaseSpec
+		typ     string
+		args    *string
+		options string
+	}{
+		{
+			name: "postgresql options",
+			spec: DatabaseSpec{PostgreSQL: &PostgreSQL{
+				PostgreSQLOptions: PostgreSQLOptions{Schemas: []string{"x"}, NoOwner: true},
+				ExtraArgs:         ptr("--foo"),
+			}},
+			typ:     "postgresql",
+			args:    ptr("--schema=x --no-owner --foo"),
+			options: `{"schemas":["x"],"noOwner":true}`,
+		},
+		{
+			name:    "mariadb options without extra args",
+			spec:    DatabaseSpec{MariaDB: &MySQL{MySQLOptions: MySQLOptions{SingleTransaction: true}}},
+			typ:     "mariadb",
+			args:    ptr("--single-transaction"),
+			options: `{"singleTransaction":true}`,
+		},
+		{
+			name: "etcd options",
+			spec: DatabaseSpec{ETCD: &ETCD{
+				ETCDOptions: ETCDOptions{DialTimeout: &metav1.Duration{Duration: time.Minute}},
+				Endpoints:   []string{"http://etcd:2379"},
+			}},
+			typ:     "etcd",
+			args:    ptr("--dial-timeout=1m0s"),
+			options: `{"dialTimeout":"1m0s"}`,
+		},
+		{
+			name: "mongodb without options",
+			spec: DatabaseSpec{MongoDB: &MongoDB{ExtraArgs: ptr("--gzip")}},
+			typ:  "mongodb",
+			args: ptr("--gzip"),
+		},
+		{
+			name: "mssql",
+			spec: DatabaseSpec{MSSQL: &MSSQL{ExtraArgs: ptr("/p:CommandTimeout=600")}},
+			typ:  "mssql",
+			args: ptr("/p:CommandTimeout=600"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := &Database{ObjectMeta: metav1.ObjectMeta{Name: "db"}, Spec: tt.spec}
+			dst := &backupv1.Database{}
+			if err := src.ConvertTo(dst); err != nil {
+				t.Fatalf("ConvertTo failed: %v", err)
+			}
+			if dst.Spec.Type != tt.typ {
+				t.Errorf("type = %q, want %q", dst.Spec.Type, tt.typ)
+			}
+			if !equality.Semantic.DeepEqual(dst.Spec.Config.Args, tt.args) {
+				t.Errorf("args = %v, want %v", deref(dst.Spec.Config.Args), deref(tt.args))
+			}
+			if got := dst.Annotations[OptionsAnnotation]; got != tt.options {
+				t.Errorf("%s = %q, want %q", OptionsAnnotation, got, tt.options)
+			}
+		})
+	}
+}
+
+func TestDatabaseRoundTripFromV2(t *testing.T) {
+	secret := &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}, Key: "password"}
+	endpoint := Endpoint{Host: ptr("db.default.svc"), Port: ptr(5432)}
+	credentials := Credentials{Username: ptr("backup"), PasswordRef: secret}
+
+	tests := []struct {
+		name string
+		spec DatabaseSpec
+	}{
+		{
+			name: "postgresql",
+			spec: DatabaseSpec{PostgreSQL: &PostgreSQL{Endpoint: endpoint, Credentials: credentials,
+				PostgreSQLOptions: PostgreSQLOptions{Schemas: []string{"app", "audit"}, NoPrivileges: true},
+				Database:          ptr("app"), ExcludeTables: []string{"sessions"}, ExtraArgs: ptr("--jobs=2")}},
+		},
+		{
+			name: "mysql",
+			spec: DatabaseSpec{MySQL: &MySQL{Endpoint: endpoint, Credentials: credentials,
+				MySQLOptions: MySQLOptions{Routines: true, Events: true},
+				Database:     ptr("app"), Tables: []string{"users"}}},
+		},
+		{
+			name: "mariadb",
+			spec: DatabaseSpec{MariaDB: &MySQL{Endpoint: endpoint, Credentials: credentials,
+				ExtraArgs: ptr("--quick")}},
+		},
+		{
+			name: "mongodb",
+			spec: DatabaseSpec{MongoDB: &MongoDB{Endpoint: endpoint, Credentials: credentials,
+				MongoDBOptions: MongoDBOptions{ExcludeCollections: []string{"logs"}, ReadPreference: "secondary"},
+				AuthDB:         ptr("admin"), Oplog: ptr(true)}},
+		},
+		{
+			name: "redis",
+			spec: DatabaseSpec{Redis: &Redis{Endpoint: endpoint, PasswordRef: secret,
+				Mode: ptr("sync"), InvokeSave: ptr(false), ExtraArgs: ptr("--tls")}},
+		},
+		{
+			name: "mssql",
+			spec: DatabaseSpec{MSSQL: &MSSQL{Endpoint: endpoint, Credentials: credentials,
+				Database: ptr("app"), TrustServerCertificate: ptr(true)}},
+		},
+		{
+			name: "influxdb",
+			spec: DatabaseSpec{InfluxDB: &InfluxDB{Endpoint: endpoint, TokenRef: secret,
+				Bucket: ptr("metrics"), Organization: ptr("acme")}},
+		},
+		{
+			name: "etcd",
+			spec: DatabaseSpec{ETCD: &ETCD{Endpoints: []string{"http://etcd-0:2379", "http://etcd-1:2379"},
+				ETCDOptions: ETCDOptions{CommandTimeout: &metav1.Duration{Duration: 5 * time.Minute}},
+				ExtraArgs:   ptr("--insecure-transport=false")}},
+		},
+		{
+			name: "exec",
+			spec: DatabaseSpec{PostgreSQL: &PostgreSQL{Endpoint: endpoint},
+				Exec: &backupv1.DatabaseExec{Selector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := &Database{
+				ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default", Labels: map[string]string{"app": "db"}},
+				Spec:       tt.spec,
+				Status:     backupv1.DatabaseStatus{Backups: []string{"nightly"}},
+			}
+			hub := &backupv1.Database{}
+			if err := src.ConvertTo(hub); err != nil {
+				t.Fatalf("ConvertTo failed: %v", err)
+			}
+			dst := &Database{}
+			if err := dst.ConvertFrom(hub); err != nil {
+				t.Fatalf("ConvertFrom failed: %v", err)
+			}
+			if !equality.Semantic.DeepEqual(src, dst) {
+				t.Errorf("round trip changed the Database:\n got %+v\nwant %+v", dst, src)
+			}
+		})
+	}
+}
+
+func TestDatabaseRoundTripFromV1(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		spec        backupv1.DatabaseSpec
+		// leftovers reports whether the v2 Database records v1 fields
+		leftovers bool
+	}{
+		{
+			name:        "postgresql with options",
+			annotations: map[string]string{OptionsAnnotation: `{"schemas":["app"],"noOwner":true}`},
+			spec: backupv1.DatabaseSpec{Type: "postgresql", Config: backupv1.DatabaseConfig{
+				Host: ptr("db"), Database: ptr("app"), Args: ptr("--schema=app --no-owner --jobs=2")}},
+		},
+		{
+			name: "mysql without options",
+			spec: backupv1.DatabaseSpec{Type: "mysql", Config: backupv1.DatabaseConfig{
+				Host: ptr("db"), Username: ptr("root"), Args: ptr("--single-transaction")}},
+		},
+		{
+			name: "mongodb",
+			spec: backupv1.DatabaseSpec{Type: "mongodb", Config: backupv1.DatabaseConfig{
+				Host: ptr("db"), AuthDB: ptr("admin"), Oplog: ptr(true)}},
+		},
+		{
+			name: "redis with sync",
+			spec: backupv1.DatabaseSpec{Type: "redis", Config: backupv1.DatabaseConfig{
+				Host: ptr("redis"), Sync: ptr(true), Copy: ptr(false), ArgsRedis: ptr("--tls")}},
+			leftovers: true,
+		},
+		{
+			name: "influxdb with args",
+			spec: backupv1.DatabaseSpec{Type: "influxdb", Config: backupv1.DatabaseConfig{
+				Host: ptr("influx"), Token: ptr("secret"), Args: ptr("--compression=gzip")}},
+			leftovers: true,
+		},
+		{
+			name: "etcd with a host",
+			spec: backupv1.DatabaseSpec{Type: "etcd", Config: backupv1.DatabaseConfig{
+				Host: ptr("etcd"), Endpoints: []string{"http://etcd:2379"}}},
+			leftovers: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := &backupv1.Database{
+				ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default", Annotations: tt.annotations},
+				Spec:       tt.spec,
+			}
+			spoke := &Database{}
+			if err := spoke.ConvertFrom(src); err != nil {
+				t.Fatalf("ConvertFrom failed: %v", err)
+			}
+			if _, ok := spoke.Annotations[OptionsAnnotation]; ok {
+				t.Errorf("v2 Database keeps %s", OptionsAnnotation)
+			}
+			if _, ok := spoke.Annotations[V1ConfigAnnotation]; ok != tt.leftovers {
+				t.Errorf("v2 Database has %s = %v, want %v", V1ConfigAnnotation, ok, tt.leftovers)
+			}
+			dst := &backupv1.Database{}
+			if err := spoke.ConvertTo(dst); err != nil {
+				t.Fatalf("ConvertTo failed: %v", err)
+			}
+			if !equality.Semantic.DeepEqual(src, dst) {
+				t.Errorf("round trip changed the Database:\n got %+v\nwant %+v", dst, src)
+			}
+		})
+	}
+}
+
+func TestDatabaseConvertFromEditedArgs(t *testing.T) {
+	src := &backupv1.Database{
+		ObjectMeta: metav1.ObjectMeta{Name: "db",
+			Annotations: map[string]string{OptionsAnnotation: `{"schemas":["app"]}`}},
+		Spec: backupv1.DatabaseSpec{Type: "postgresql", Config: backupv1.DatabaseConfig{
+			Args: ptr("--schema=other")}},
+	}
+	dst := &Database{}
+	if err := dst.ConvertFrom(src); err != nil {
+		t.Fatalf("ConvertFrom failed: %v", err)
+	}
+	if schemas := dst.Spec.PostgreSQL.Schemas; schemas != nil {
+		t.Errorf("schemas = %v, want none", schemas)
+	}
+	if args := deref(dst.Spec.PostgreSQL.ExtraArgs); args != "--schema=other" {
+		t.Errorf("extraArgs = %q, want %q", args, "--schema=other")
+	}
+}
+
+func TestDatabaseConversionErrors(t *testing.T) {
+	if err := (&Database{}).ConvertTo(&backupv1.Database{}); err == nil {
+		t.Error("ConvertTo of a Database without engine succeeded")
+	}
+	src := &backupv1.Database{Spec: backupv1.DatabaseSpec{Type: "cassandra"}}
+	if err := (&Database{}).ConvertFrom(src); err == nil {
+		t.Error("ConvertFrom of a Database of unknown type succeeded")
+	}
+}
+
+// deref returns the value of s, or ""
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
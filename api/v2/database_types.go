@@ -0,0 +1,362 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+)
+
+// DatabaseSpec defines the desired state of Database: exactly one engine
+// +kubebuilder:validation:XValidation:rule="[has(self.postgresql), has(self.mysql), has(self.mariadb), has(self.mongodb), has(self.redis), has(self.mssql), has(self.influxdb), has(self.etcd)].filter(x, x).size() == 1",message="set exactly one of postgresql, mysql, mariadb, mongodb, redis, mssql, influxdb and etcd"
+// +kubebuilder:validation:XValidation:rule="!has(self.exec) || has(self.exec.command) || has(self.postgresql) || has(self.mysql) || has(self.mariadb) || has(self.mongodb) || has(self.redis)",message="exec.command is required for this database engine"
+type DatabaseSpec struct {
+	// PostgreSQL is dumped with pg_dump
+	// +optional
+	PostgreSQL *PostgreSQL `json:"postgresql,omitempty"`
+
+	// MySQL is dumped with mysqldump
+	// +optional
+	MySQL *MySQL `json:"mysql,omitempty"`
+
+	// MariaDB is dumped with mysqldump
+	// +optional
+	MariaDB *MySQL `json:"mariadb,omitempty"`
+
+	// MongoDB is dumped with mongodump
+	// +optional
+	MongoDB *MongoDB `json:"mongodb,omitempty"`
+
+	// Redis is dumped by copying or syncing its RDB file
+	// +optional
+	Redis *Redis `json:"redis,omitempty"`
+
+	// MSSQL is dumped with sqlpackage
+	// +optional
+	MSSQL *MSSQL `json:"mssql,omitempty"`
+
+	// InfluxDB is dumped with influx backup
+	// +optional
+	InfluxDB *InfluxDB `json:"influxdb,omitempty"`
+
+	// ETCD is dumped with etcdctl snapshot save
+	// +optional
+	ETCD *ETCD `json:"etcd,omitempty"`
+
+	// Exec dumps the database by running a command in its pod through
+	// pods/exec, instead of connecting over the network
+	// +optional
+	Exec *backupv1.DatabaseExec `json:"exec,omitempty"`
+}
+
+// Endpoint locates a database server
+// +kubebuilder:validation:XValidation:rule="!has(self.serviceRef) || (!has(self.host) && !has(self.port) && !has(self.socket))",message="serviceRef replaces host, port and socket"
+// +kubebuilder:validation:XValidation:rule="!has(self.serviceRef) || !has(self.fromObject)",message="set at most one of serviceRef and fromObject"
+type Endpoint struct {
+	// Host is the database server hostname
+	// +optional
+	Host *string `json:"host,omitempty"`
+
+	// Port is the database server port
+	// +optional
+	Port *int `json:"port,omitempty"`
+
+	// Socket is the database server socket
+	// +optional
+	Socket *string `json:"socket,omitempty"`
+
+	// ServiceRef resolves host and port from a Service
+	// +optional
+	ServiceRef *backupv1.DatabaseServiceRef `json:"serviceRef,omitempty"`
+
+	// FromObject reads connection settings from an object in the namespace
+	// of the Database. Mapped fields override those set here.
+	// +optional
+	FromObject *backupv1.DatabaseObjectRef `json:"fromObject,omitempty"`
+}
+
+// Credentials authenticate to a database server
+// +kubebuilder:validation:XValidation:rule="!has(self.username) || !has(self.usernameRef)",message="set at most one of username and usernameRef"
+// +kubebuilder:validation:XValidation:rule="!has(self.password) || !has(self.passwordRef)",message="set at most one of password and passwordRef"
+type Credentials struct {
+	// Username is the user to connect as
+	// +optional
+	Username *string `json:"username,omitempty"`
+
+	// UsernameRef references a Secret containing the username
+	// +optional
+	UsernameRef *corev1.SecretKeySelector `json:"usernameRef,omitempty"`
+
+	// Password is the password of the user. Use passwordRef instead.
+	// +optional
+	Password *string `json:"password,omitempty"`
+
+	// PasswordRef references a Secret containing the password
+	// +optional
+	PasswordRef *corev1.SecretKeySelector `json:"passwordRef,omitempty"`
+}
+
+// PostgreSQL is a PostgreSQL server
+type PostgreSQL struct {
+	Endpoint          `json:",inline"`
+	Credentials       `json:",inline"`
+	PostgreSQLOptions `json:",inline"`
+
+	// Database is the database to dump
+	// +optional
+	Database *string `json:"database,omitempty"`
+
+	// Tables are the tables to dump. Default: all
+	// +optional
+	Tables []string `json:"tables,omitempty"`
+
+	// ExcludeTables are tables left out of the dump
+	// +optional
+	ExcludeTables []string `json:"excludeTables,omitempty"`
+
+	// ExtraArgs are more pg_dump arguments
+	// +optional
+	ExtraArgs *string `json:"extraArgs,omitempty"`
+}
+
+// PostgreSQLOptions are pg_dump options
+type PostgreSQLOptions struct {
+	// Schemas are the schemas to dump (--schema). Default: all
+	// +optional
+	Schemas []string `json:"schemas,omitempty"`
+
+	// NoOwner leaves ownership out of the dump (--no-owner)
+	// +optional
+	NoOwner bool `json:"noOwner,omitempty"`
+
+	// NoPrivileges leaves grants out of the dump (--no-privileges)
+	// +optional
+	NoPrivileges bool `json:"noPrivileges,omitempty"`
+}
+
+// MySQL is a MySQL or MariaDB server
+type MySQL struct {
+	Endpoint     `json:",inline"`
+	Credentials  `json:",inline"`
+	MySQLOptions `json:",inline"`
+
+	// Database is the database to dump
+	// +optional
+	Database *string `json:"database,omitempty"`
+
+	// Tables are the tables to dump. Default: all
+	// +optional
+	Tables []string `json:"tables,omitempty"`
+
+	// ExcludeTables are tables left out of the dump
+	// +optional
+	ExcludeTables []string `json:"excludeTables,omitempty"`
+
+	// ExtraArgs are more mysqldump arguments
+	// +optional
+	ExtraArgs *string `json:"extraArgs,omitempty"`
+}
+
+// MySQLOptions are mysqldump options
+type MySQLOptions struct {
+	// SingleTransaction dumps InnoDB tables consistently without locking
+	// them (--single-transaction)
+	// +optional
+	SingleTransaction bool `json:"singleTransaction,omitempty"`
+
+	// Routines dumps stored procedures and functions (--routines)
+	// +optional
+	Routines bool `json:"routines,omitempty"`
+
+	// Events dumps scheduled events (--events)
+	// +optional
+	Events bool `json:"events,omitempty"`
+
+	// SkipSSL connects without TLS (--skip-ssl)
+	// +optional
+	SkipSSL bool `json:"skipSSL,omitempty"`
+}
+
+// MongoDB is a MongoDB server
+type MongoDB struct {
+	Endpoint       `json:",inline"`
+	Credentials    `json:",inline"`
+	MongoDBOptions `json:",inline"`
+
+	// Database is the database to dump. Default: all
+	// +optional
+	Database *string `json:"database,omitempty"`
+
+	// AuthDB is the authentication database
+	// +optional
+	AuthDB *string `json:"authDB,omitempty"`
+
+	// Oplog dumps the oplog for a point-in-time snapshot
+	// +optional
+	Oplog *bool `json:"oplog,omitempty"`
+
+	// ExtraArgs are more mongodump arguments
+	// +optional
+	ExtraArgs *string `json:"extraArgs,omitempty"`
+}
+
+// MongoDBOptions are mongodump options
+type MongoDBOptions struct {
+	// ExcludeCollections are collections left out of the dump
+	// (--excludeCollection)
+	// +optional
+	ExcludeCollections []string `json:"excludeCollections,omitempty"`
+
+	// ReadPreference selects the members read from (--readPreference)
+	// +kubebuilder:validation:Enum=primary;primaryPreferred;secondary;secondaryPreferred;nearest
+	// +optional
+	ReadPreference string `json:"readPreference,omitempty"`
+}
+
+// Redis is a Redis server
+type Redis struct {
+	Endpoint `json:",inline"`
+
+	// Password is the password of the server. Use passwordRef instead.
+	// +optional
+	Password *string `json:"password,omitempty"`
+
+	// PasswordRef references a Secret containing the password
+	// +optional
+	PasswordRef *corev1.SecretKeySelector `json:"passwordRef,omitempty"`
+
+	// Mode is copy, to copy the RDB file, or sync, to fetch it from the
+	// server. Default: copy
+	// +kubebuilder:validation:Enum=copy;sync
+	// +optional
+	Mode *string `json:"mode,omitempty"`
+
+	// InvokeSave saves the dataset before it is dumped. Default: true
+	// +optional
+	InvokeSave *bool `json:"invokeSave,omitempty"`
+
+	// RdbPath is the path of the RDB file in copy mode. Default:
+	// /var/lib/redis/dump.rdb
+	// +optional
+	RdbPath *string `json:"rdbPath,omitempty"`
+
+	// RdbSource locates the volume holding rdbPath in copy mode
+	// +optional
+	RdbSource *backupv1.RedisRdbSource `json:"rdbSource,omitempty"`
+
+	// ExtraArgs are more redis-cli arguments, such as --tls
+	// +optional
+	ExtraArgs *string `json:"extraArgs,omitempty"`
+}
+
+// MSSQL is a Microsoft SQL Server
+type MSSQL struct {
+	Endpoint    `json:",inline"`
+	Credentials `json:",inline"`
+
+	// Database is the database to dump
+	// +optional
+	Database *string `json:"database,omitempty"`
+
+	// TrustServerCertificate skips the validation of the server certificate
+	// +optional
+	TrustServerCertificate *bool `json:"trustServerCertificate,omitempty"`
+
+	// ExtraArgs are more sqlpackage arguments
+	// +optional
+	ExtraArgs *string `json:"extraArgs,omitempty"`
+}
+
+// InfluxDB is an InfluxDB 2 server
+// +kubebuilder:validation:XValidation:rule="!has(self.token) || !has(self.tokenRef)",message="set at most one of token and tokenRef"
+type InfluxDB struct {
+	Endpoint `json:",inline"`
+
+	// Token is the authentication token. Use tokenRef instead.
+	// +optional
+	Token *string `json:"token,omitempty"`
+
+	// TokenRef references a Secret containing the authentication token
+	// +optional
+	TokenRef *corev1.SecretKeySelector `json:"tokenRef,omitempty"`
+
+	// Bucket is the bucket to dump. Default: all
+	// +optional
+	Bucket *string `json:"bucket,omitempty"`
+
+	// Organization is the organization of the bucket
+	// +optional
+	Organization *string `json:"org,omitempty"`
+}
+
+// ETCD is an etcd cluster
+type ETCD struct {
+	ETCDOptions `json:",inline"`
+
+	// Endpoints are the endpoints of the cluster
+	// +optional
+	Endpoints []string `json:"endpoints,omitempty"`
+
+	// ExtraArgs are more etcdctl arguments
+	// +optional
+	ExtraArgs *string `json:"extraArgs,omitempty"`
+}
+
+// ETCDOptions are etcdctl options
+type ETCDOptions struct {
+	// DialTimeout bounds connecting to the cluster (--dial-timeout)
+	// +optional
+	DialTimeout *metav1.Duration `json:"dialTimeout,omitempty"`
+
+	// CommandTimeout bounds taking the snapshot (--command-timeout)
+	// +optional
+	CommandTimeout *metav1.Duration `json:"commandTimeout,omitempty"`
+}
+
+//+kubebuilder:resource:shortName=db
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Message",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].message`,priority=1
+//+kubebuilder:printcolumn:name="Backups",type=string,JSONPath=`.status.backups`,priority=1
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:unservedversion
+
+// Database is the Schema for the databases API, with a typed struct per
+// engine. It is served once the conversion webhook is enabled.
+type Database struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DatabaseSpec            `json:"spec,omitempty"`
+	Status backupv1.DatabaseStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// DatabaseList contains a list of Database
+type DatabaseList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Database `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Database{}, &DatabaseList{})
+}
@@ -17,24 +17,32 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
+	"path/filepath"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
-
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	// Embed the IANA time zone database for spec.schedule.timeZone
+	_ "time/tzdata"
+
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
 	gobackupiov1 "github.com/gobackup/gobackup-operator/api/v1"
+	backupv2 "github.com/gobackup/gobackup-operator/api/v2"
 	"github.com/gobackup/gobackup-operator/internal/controller"
 	"github.com/gobackup/gobackup-operator/pkg/k8sutil"
 	//+kubebuilder:scaffold:imports
@@ -50,6 +58,7 @@ func init() {
 
 	utilruntime.Must(backupv1.AddToScheme(scheme))
 	utilruntime.Must(gobackupiov1.AddToScheme(scheme))
+	utilruntime.Must(backupv2.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -57,11 +66,14 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var webhookCertDir string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&webhookCertDir, "webhook-cert-dir", filepath.Join(os.TempDir(), "k8s-webhook-server", "serving-certs"),
+		"The directory holding tls.crt, tls.key and ca.crt of the webhook server.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -74,6 +86,7 @@ func main() {
 		Scheme:                 scheme,
 		Metrics:                metricsserver.Options{BindAddress: metricsAddr},
 		HealthProbeBindAddress: probeAddr,
+		WebhookServer:          webhook.NewServer(webhook.Options{CertDir: webhookCertDir}),
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "4f5a4869.github.com",
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
@@ -87,6 +100,12 @@ func main() {
 		// if you are doing or is intended to do any operation such as perform cleanups
 		// after the manager stops then its usage might be unsafe.
 		// LeaderElectionReleaseOnCancel: true,
+
+		// Pods are read for a few Jobs and claims only; caching them would
+		// watch every pod in the cluster
+		Client: client.Options{
+			Cache: &client.CacheOptions{DisableFor: []client.Object{&corev1.Pod{}}},
+		},
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
@@ -115,10 +134,87 @@ func main() {
 		Scheme:    mgr.GetScheme(),
 		K8s:       k8s,
 		Clientset: clientset,
+		Recorder:  mgr.GetEventRecorder("backup-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Backup")
 		os.Exit(1)
 	}
+	if err = (&controller.BackupRunReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		K8s:       k8s,
+		Clientset: clientset,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BackupRun")
+		os.Exit(1)
+	}
+	if err = (&controller.RestoreReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		K8s:       k8s,
+		Clientset: clientset,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Restore")
+		os.Exit(1)
+	}
+	if err = (&controller.BackupArtifactReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+		K8s:    k8s,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BackupArtifact")
+		os.Exit(1)
+	}
+	if err = (&controller.DatabaseReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+		K8s:    k8s,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Database")
+		os.Exit(1)
+	}
+	if err = (&controller.StorageReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+		K8s:    k8s,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Storage")
+		os.Exit(1)
+	}
+	queueLimits, err := controller.LoadQueueLimits()
+	if err != nil {
+		setupLog.Error(err, "invalid backup queue configuration")
+		os.Exit(1)
+	}
+	// Registered even without limits, to release Jobs queued before the
+	// limits were removed
+	if err = (&controller.BackupQueueReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+		Limits: queueLimits,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BackupQueue")
+		os.Exit(1)
+	}
+	// The conversion webhook serves gobackup.io/v2 Databases
+	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
+		if err = ctrl.NewWebhookManagedBy(mgr, &backupv1.Database{}).Complete(); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Database")
+			os.Exit(1)
+		}
+		// Installs whose CRDs are not patched for the webhook name its Service
+		if service := os.Getenv("WEBHOOK_SERVICE_NAME"); service != "" {
+			caBundle, err := os.ReadFile(filepath.Join(webhookCertDir, "ca.crt"))
+			if err != nil {
+				setupLog.Error(err, "unable to read the CA of the webhook server")
+				os.Exit(1)
+			}
+			if err := k8s.EnableDatabaseConversion(context.Background(), os.Getenv("WEBHOOK_SERVICE_NAMESPACE"), service, caBundle); err != nil {
+				setupLog.Error(err, "unable to enable conversion of Databases")
+				os.Exit(1)
+			}
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
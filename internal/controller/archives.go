@@ -0,0 +1,171 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+	"github.com/gobackup/gobackup-operator/pkg/k8sutil"
+)
+
+// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch
+
+// volatilePodLabels change with every rollout of a workload, so pod
+// affinities to archived claims leave them out
+var volatilePodLabels = []string{"pod-template-hash", "controller-revision-hash", "pod-template-generation"}
+
+// applyArchives mounts the claims of spec.archives read-only into the
+// gobackup container of a pod. Pods are scheduled onto the node of the pods
+// using ReadWriteOnce claims, as recorded in status.archives.
+func applyArchives(spec *corev1.PodSpec, backup *backupv1.Backup) {
+	container := &spec.Containers[0]
+	for i, archive := range backup.Spec.Archives {
+		name := fmt.Sprintf("archive-%d", i)
+		spec.Volumes = append(spec.Volumes, corev1.Volume{
+			Name: name,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: archive.ClaimName, ReadOnly: true},
+			},
+		})
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      name,
+			MountPath: k8sutil.ArchiveMountPath(archive.ClaimName),
+			ReadOnly:  true,
+		})
+	}
+
+	for _, claim := range backup.Status.Archives {
+		if claim.ReadWriteOnce {
+			requireNodeOf(spec, claim.MountedBy)
+		}
+	}
+}
+
+// requireNodeOf schedules a pod onto the node of the pods with the labels
+func requireNodeOf(spec *corev1.PodSpec, labels map[string]string) {
+	if len(labels) == 0 {
+		return
+	}
+	if spec.Affinity == nil {
+		spec.Affinity = &corev1.Affinity{}
+	}
+	if spec.Affinity.PodAffinity == nil {
+		spec.Affinity.PodAffinity = &corev1.PodAffinity{}
+	}
+	spec.Affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution = append(
+		spec.Affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution,
+		corev1.PodAffinityTerm{
+			LabelSelector: &metav1.LabelSelector{MatchLabels: labels},
+			TopologyKey:   corev1.LabelHostname,
+		})
+}
+
+// reconcileArchives records in status.archives whether the claims of
+// spec.archives are ReadWriteOnce, and the labels of the pods using them
+func (r *BackupReconciler) reconcileArchives(ctx context.Context, backup *backupv1.Backup) error {
+	var claims []backupv1.ArchiveClaimStatus
+	var pods *corev1.PodList
+	for _, archive := range backup.Spec.Archives {
+		status := backupv1.ArchiveClaimStatus{ClaimName: archive.ClaimName}
+		claim := &corev1.PersistentVolumeClaim{}
+		err := r.Get(ctx, types.NamespacedName{Name: archive.ClaimName, Namespace: backup.Namespace}, claim)
+		if errors.IsNotFound(err) {
+			status.Message = "PersistentVolumeClaim not found"
+			claims = append(claims, status)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get PersistentVolumeClaim %s: %w", archive.ClaimName, err)
+		}
+
+		status.ReadWriteOnce = readWriteOnce(claim)
+		if slices.Contains(claim.Spec.AccessModes, corev1.ReadWriteOncePod) {
+			status.Message = "ReadWriteOncePod claims cannot be mounted by backup Jobs while in use"
+		}
+
+		if status.ReadWriteOnce {
+			if pods == nil {
+				pods = &corev1.PodList{}
+				if err := r.List(ctx, pods, client.InNamespace(backup.Namespace)); err != nil {
+					return fmt.Errorf("failed to list pods: %w", err)
+				}
+			}
+			status.MountedBy = claimConsumerLabels(pods.Items, archive.ClaimName)
+		}
+		claims = append(claims, status)
+	}
+
+	if equality.Semantic.DeepEqual(backup.Status.Archives, claims) {
+		return nil
+	}
+	patch := client.MergeFrom(backup.DeepCopy())
+	backup.Status.Archives = claims
+	if err := r.Status().Patch(ctx, backup, patch); err != nil {
+		return fmt.Errorf("failed to update archive status: %w", err)
+	}
+	return nil
+}
+
+// claimConsumerLabels returns the stable labels of the first running pod, by
+// name, other than a backup pod, that mounts the claim, or nil
+func claimConsumerLabels(pods []corev1.Pod, claimName string) map[string]string {
+	pods = slices.Clone(pods)
+	slices.SortFunc(pods, func(a, b corev1.Pod) int { return strings.Compare(a.Name, b.Name) })
+	for _, pod := range pods {
+		if _, ok := pod.Labels[BackupLabel]; ok || pod.Status.Phase != corev1.PodRunning || len(pod.Labels) == 0 {
+			continue
+		}
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim == nil || volume.PersistentVolumeClaim.ClaimName != claimName {
+				continue
+			}
+			if labels := stablePodLabels(&pod); len(labels) > 0 {
+				return labels
+			}
+		}
+	}
+	return nil
+}
+
+// stablePodLabels returns the labels of a pod without volatilePodLabels
+func stablePodLabels(pod *corev1.Pod) map[string]string {
+	labels := map[string]string{}
+	for k, v := range pod.Labels {
+		if !slices.Contains(volatilePodLabels, k) {
+			labels[k] = v
+		}
+	}
+	return labels
+}
+
+// readWriteOnce reports whether a claim can only be mounted on one node
+func readWriteOnce(claim *corev1.PersistentVolumeClaim) bool {
+	return slices.Contains(claim.Spec.AccessModes, corev1.ReadWriteOnce) &&
+		!slices.Contains(claim.Spec.AccessModes, corev1.ReadOnlyMany) &&
+		!slices.Contains(claim.Spec.AccessModes, corev1.ReadWriteMany)
+}
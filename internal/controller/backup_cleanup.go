@@ -0,0 +1,252 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"path"
+	"slices"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+)
+
+const (
+	// CleanupFinalizer holds a Backup until the cleanup requested by its
+	// spec.deletionPolicy is done
+	CleanupFinalizer = "gobackup.io/cleanup"
+
+	// OrphanedJobGracePeriod is how long a finished Job without an owner is
+	// kept before pruneOrphanedJobs deletes it
+	OrphanedJobGracePeriod = 10 * time.Minute
+
+	// ReasonArtifactsRetained is the Event reason for archives left in a
+	// Storage the cleanup of a deleted Backup cannot open
+	ReasonArtifactsRetained = "ArtifactsRetained"
+)
+
+// +kubebuilder:rbac:groups=gobackup.io,resources=backupartifacts,verbs=get;list;watch;delete
+
+// ensureCleanupFinalizer adds the cleanup finalizer to a Backup that is not
+// being deleted
+func (r *BackupReconciler) ensureCleanupFinalizer(ctx context.Context, backup *backupv1.Backup) error {
+	if controllerutil.ContainsFinalizer(backup, CleanupFinalizer) {
+		return nil
+	}
+	controllerutil.AddFinalizer(backup, CleanupFinalizer)
+	if err := r.Update(ctx, backup); err != nil {
+		return fmt.Errorf("failed to add cleanup finalizer: %w", err)
+	}
+	return nil
+}
+
+// handleBackupDeletion runs the cleanup selected by spec.deletionPolicy and
+// releases the finalizer once it succeeded. Objects owned by the Backup (the
+// CronJob, the config Secret and BackupRuns) are left to garbage collection.
+func (r *BackupReconciler) handleBackupDeletion(ctx context.Context, backup *backupv1.Backup) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	forgetRPOMetrics(backup)
+	if !controllerutil.ContainsFinalizer(backup, CleanupFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	policy := backup.Spec.DeletionPolicy
+	if policy == "" {
+		policy = backupv1.DeletionPolicyRetain
+	}
+	logger.Info("Cleaning up deleted Backup", "name", backup.Name, "deletionPolicy", policy)
+
+	// Purge archives first: while a Storage is unreachable the Backup, its
+	// runs and its Jobs stay, and the purge is retried with backoff
+	if policy == backupv1.DeletionPolicyDeleteArtifacts {
+		if err := r.deleteBackupArtifacts(ctx, backup); err != nil {
+			logger.Error(err, "Failed to delete backup artifacts")
+			return ctrl.Result{}, err
+		}
+	}
+
+	if policy == backupv1.DeletionPolicyDeleteRuntime || policy == backupv1.DeletionPolicyDeleteArtifacts {
+		if err := r.deleteBackupJobs(ctx, backup); err != nil {
+			logger.Error(err, "Failed to delete backup jobs")
+			return ctrl.Result{}, err
+		}
+	}
+
+	controllerutil.RemoveFinalizer(backup, CleanupFinalizer)
+	if err := r.Update(ctx, backup); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to remove cleanup finalizer: %w", err)
+	}
+	logger.Info("Backup cleanup completed", "name", backup.Name)
+	return ctrl.Result{}, nil
+}
+
+// deleteBackupJobs deletes every Job of the Backup, including manual Jobs left
+// behind by CronJobs that were replaced with orphan propagation
+func (r *BackupReconciler) deleteBackupJobs(ctx context.Context, backup *backupv1.Backup) error {
+	jobs, err := r.listBackupJobs(ctx, backup)
+	if err != nil {
+		return err
+	}
+
+	for i := range jobs {
+		if err := r.Delete(ctx, &jobs[i], client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete job %s: %w", jobs[i].Name, err)
+		}
+	}
+	return nil
+}
+
+// pruneOrphanedJobs deletes finished Jobs of the Backup that lost their owner,
+// once they had the time to be recorded as BackupRuns. Such Jobs were left
+// behind by CronJobs deleted with Orphan propagation, e.g. by operator
+// versions that recreated the CronJob on every edit, and are never garbage
+// collected otherwise. Those versions did not label their manual Jobs, so
+// unlabelled Jobs are pruned only when isLegacyManualJob matches them.
+func (r *BackupReconciler) pruneOrphanedJobs(ctx context.Context, backup *backupv1.Backup) error {
+	jobs, err := r.listBackupJobs(ctx, backup)
+	if err != nil {
+		return err
+	}
+
+	for i := range jobs {
+		job := &jobs[i]
+		if metav1.GetControllerOf(job) != nil {
+			continue
+		}
+		if _, ok := job.Labels[BackupLabel]; !ok && !isLegacyManualJob(job, backup) {
+			continue
+		}
+		finishedAt := jobFinishedAt(job)
+		if finishedAt == nil || time.Since(finishedAt.Time) < OrphanedJobGracePeriod {
+			continue
+		}
+		if err := r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete orphaned job %s: %w", job.Name, err)
+		}
+		log.FromContext(ctx).Info("Deleted orphaned backup job", "job", job.Name)
+	}
+	return nil
+}
+
+// jobFinishedAt returns when a Job completed or failed, or nil while it runs
+func jobFinishedAt(job *batchv1.Job) *metav1.Time {
+	for _, condition := range job.Status.Conditions {
+		if (condition.Type == batchv1.JobComplete || condition.Type == batchv1.JobFailed) && condition.Status == corev1.ConditionTrue {
+			return &condition.LastTransitionTime
+		}
+	}
+	return nil
+}
+
+// isUnusableStorage reports whether openStorage failed for a reason retrying
+// does not fix: the Storage or a Secret it references is gone, the operator
+// may not read them, or storage.New rejected the Storage
+func isUnusableStorage(err error) bool {
+	var configErr *storageConfigError
+	return errors.IsNotFound(err) || errors.IsForbidden(err) || errors.IsUnauthorized(err) || stderrors.As(err, &configErr)
+}
+
+// deleteBackupArtifacts deletes the archives of the Backup from every Storage
+// it references, together with their BackupArtifacts. Archives are those
+// recorded by the Backup's runs and those the inventory attributed to it;
+// archives of other Backups sharing a Storage are never touched.
+func (r *BackupReconciler) deleteBackupArtifacts(ctx context.Context, backup *backupv1.Backup) error {
+	logger := log.FromContext(ctx)
+
+	keys := map[string]map[string]bool{}
+	add := func(storageName, key string) {
+		if keys[storageName] == nil {
+			keys[storageName] = map[string]bool{}
+		}
+		keys[storageName][key] = true
+	}
+
+	runs := &backupv1.BackupRunList{}
+	if err := r.List(ctx, runs, client.InNamespace(backup.Namespace)); err != nil {
+		return fmt.Errorf("failed to list backup runs: %w", err)
+	}
+	for _, run := range runs.Items {
+		if run.Spec.BackupRef.Name != backup.Name || run.Status.Artifact == nil {
+			continue
+		}
+		for _, storageName := range run.Status.Artifact.Storages {
+			add(storageName, run.Status.Artifact.Filename)
+		}
+	}
+
+	artifacts := &backupv1.BackupArtifactList{}
+	if err := r.List(ctx, artifacts, client.InNamespace(backup.Namespace), client.MatchingLabels{BackupLabel: backup.Name}); err != nil {
+		return fmt.Errorf("failed to list backup artifacts: %w", err)
+	}
+	for _, artifact := range artifacts.Items {
+		add(artifact.Spec.StorageRef.Name, artifact.Spec.Key)
+	}
+
+	for _, ref := range backup.Spec.StorageRefs {
+		if len(keys[ref.Name]) == 0 {
+			continue
+		}
+
+		var dirs []string
+		for key := range keys[ref.Name] {
+			if dir := path.Dir(key); dir != "." && !slices.Contains(dirs, dir) {
+				dirs = append(dirs, dir)
+			}
+		}
+		store, err := r.openStorage(ctx, backup.Namespace, ref, dirs)
+		if err != nil {
+			if !isUnusableStorage(err) {
+				return err
+			}
+			// Retrying would hold the Backup in Terminating forever
+			logger.Info("Storage cannot be opened, skipping its artifacts", "storage", ref.Name, "reason", err.Error())
+			r.Recorder.Eventf(backup, nil, corev1.EventTypeWarning, ReasonArtifactsRetained, "DeleteArtifacts",
+				"Archives in storage %s were not deleted: %v", ref.Name, err)
+			continue
+		}
+
+		for key := range keys[ref.Name] {
+			if err := store.Delete(ctx, key); err != nil {
+				return fmt.Errorf("failed to delete artifact %s from storage %s: %w", key, ref.Name, err)
+			}
+			logger.Info("Deleted artifact from storage", "storage", ref.Name, "key", key)
+
+			artifact := &backupv1.BackupArtifact{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      artifactObjectName(ref.Name, key),
+					Namespace: backup.Namespace,
+				},
+			}
+			if err := r.Delete(ctx, artifact); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("failed to delete backup artifact %s: %w", artifact.Name, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+)
+
+// testJob returns a Job with the given labels and annotations, controlled by
+// the owner of the given kind and name when kind is set
+func testJob(name string, labels, annotations map[string]string, kind, owner string) *batchv1.Job {
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+		Name: name, Namespace: "default", Labels: labels, Annotations: annotations,
+	}}
+	if kind != "" {
+		controller := true
+		job.OwnerReferences = []metav1.OwnerReference{{APIVersion: "v1", Kind: kind, Name: owner,
+			UID: types.UID(kind + "-" + owner), Controller: &controller}}
+	}
+	return job
+}
+
+func TestIsLegacyBackupJob(t *testing.T) {
+	backup := &backupv1.Backup{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: "Backup-app"}}
+	manual := map[string]string{ManualJobAnnotation: "manual"}
+
+	tests := []struct {
+		name string
+		job  *batchv1.Job
+		want bool
+	}{
+		{name: "scheduled Job of the CronJob", job: testJob("app-28512345", nil, nil, "CronJob", "app"), want: true},
+		{name: "manual Job of the CronJob", job: testJob("app-manual-1718413200", nil, manual, "CronJob", "app"), want: true},
+		{name: "manual Job owned by the Backup", job: testJob("app-manual-1718413200", nil, manual, "Backup", "app"), want: true},
+		{name: "orphaned scheduled Job", job: testJob("app-28512345", nil, nil, "", ""), want: true},
+		{name: "orphaned manual Job", job: testJob("app-manual-1718413200", nil, manual, "", ""), want: true},
+		{name: "user Job sharing the prefix", job: testJob("app-migrate", nil, nil, "", ""), want: false},
+		{name: "Job of another Backup's CronJob", job: testJob("app-prod-28512345", nil, nil, "CronJob", "app-prod"), want: false},
+		{name: "orphaned Job of another Backup", job: testJob("app-prod-28512345", nil, nil, "", ""), want: false},
+		{name: "manual name without the annotation", job: testJob("app-manual-1718413200", nil, nil, "", ""), want: false},
+		{name: "timestamp owned by another CronJob", job: testJob("app-28512345", nil, nil, "CronJob", "other"), want: false},
+		{name: "empty suffix", job: testJob("app-", nil, nil, "", ""), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isLegacyBackupJob(tt.job, backup); got != tt.want {
+				t.Errorf("isLegacyBackupJob(%s) = %v, want %v", tt.job.Name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeleteBackupJobs(t *testing.T) {
+	backup := &backupv1.Backup{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: "Backup-app"}}
+	c := newTestClient(t,
+		testJob("app-oneshot-1", map[string]string{BackupLabel: "app"}, nil, "Backup", "app"),
+		testJob("app-28512345", nil, nil, "CronJob", "app"),
+		testJob("app-manual-1718413200", nil, map[string]string{ManualJobAnnotation: "manual"}, "", ""),
+		testJob("app-migrate", nil, nil, "", ""),
+		testJob("app-prod-28512345", nil, nil, "CronJob", "app-prod"),
+		testJob("app-prod-oneshot-1", map[string]string{BackupLabel: "app-prod"}, nil, "Backup", "app-prod"),
+	)
+	r := &BackupReconciler{Client: c, Scheme: c.Scheme()}
+
+	if err := r.deleteBackupJobs(context.Background(), backup); err != nil {
+		t.Fatalf("deleteBackupJobs failed: %v", err)
+	}
+
+	jobs := &batchv1.JobList{}
+	if err := c.List(context.Background(), jobs); err != nil {
+		t.Fatalf("failed to list jobs: %v", err)
+	}
+	var names []string
+	for _, job := range jobs.Items {
+		names = append(names, job.Name)
+	}
+	slices.Sort(names)
+	want := []string{"app-migrate", "app-prod-28512345", "app-prod-oneshot-1"}
+	if !slices.Equal(names, want) {
+		t.Errorf("remaining jobs = %v, want %v", names, want)
+	}
+}
+
+// finished marks a Job complete at the given time
+func finished(job *batchv1.Job, at time.Time) *batchv1.Job {
+	job.Status.Conditions = []batchv1.JobCondition{{
+		Type: batchv1.JobComplete, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(at),
+	}}
+	return job
+}
+
+func TestPruneOrphanedJobs(t *testing.T) {
+	backup := &backupv1.Backup{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: "Backup-app"}}
+	manual := map[string]string{ManualJobAnnotation: "manual"}
+	labels := map[string]string{BackupLabel: "app"}
+	old := time.Now().Add(-2 * OrphanedJobGracePeriod)
+
+	c := newTestClient(t,
+		// As created by triggerManualBackupJob before Jobs were labelled,
+		// after its CronJob was deleted with Orphan propagation
+		finished(testJob("app-manual-1718413200", nil, manual, "", ""), old),
+		finished(testJob("app-manual-1718413300", labels, manual, "", ""), old),
+		finished(testJob("app-manual-1718413400", nil, manual, "", ""), time.Now()),
+		testJob("app-manual-1718413500", nil, manual, "", ""),
+		finished(testJob("app-manual-1718413600", nil, manual, "CronJob", "app"), old),
+		finished(testJob("app-28512345", nil, nil, "", ""), old),
+		finished(testJob("app-migrate", nil, nil, "", ""), old),
+	)
+	r := &BackupReconciler{Client: c, Scheme: c.Scheme()}
+
+	if err := r.pruneOrphanedJobs(context.Background(), backup); err != nil {
+		t.Fatalf("pruneOrphanedJobs failed: %v", err)
+	}
+
+	jobs := &batchv1.JobList{}
+	if err := c.List(context.Background(), jobs); err != nil {
+		t.Fatalf("failed to list jobs: %v", err)
+	}
+	var names []string
+	for _, job := range jobs.Items {
+		names = append(names, job.Name)
+	}
+	slices.Sort(names)
+	want := []string{"app-28512345", "app-manual-1718413400", "app-manual-1718413500", "app-manual-1718413600", "app-migrate"}
+	if !slices.Equal(names, want) {
+		t.Errorf("remaining jobs = %v, want %v", names, want)
+	}
+}
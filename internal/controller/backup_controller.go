@@ -19,9 +19,12 @@ package controller
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -32,6 +35,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -48,6 +52,7 @@ type BackupReconciler struct {
 	Scheme    *runtime.Scheme
 	K8s       *k8sutil.K8s
 	Clientset *kubernetes.Clientset
+	Recorder  events.EventRecorder
 }
 
 const (
@@ -57,12 +62,37 @@ const (
 	MaxLogSize = 4096
 	// MaxMessageSize is the maximum size of message to store in status (1KB)
 	MaxMessageSize = 1024
+
+	// TriggerAnnotation requests another run of an unscheduled (one-shot) Backup.
+	// Setting it to a value it has not held before starts a new Job; the Job is
+	// named after the value, so a reused value finds the Job of its earlier run.
+	TriggerAnnotation = "gobackup.io/trigger"
+
+	// BackupLabel is set on every Job created for a Backup and on its BackupRuns
+	BackupLabel = "gobackup.io/backup"
+	// BackupRunLabel is set on Jobs created for an on-demand BackupRun
+	BackupRunLabel = "gobackup.io/backup-run"
+	// TierLabel is set on the CronJobs and Jobs of a schedule tier
+	TierLabel = "gobackup.io/tier"
+
+	// RecordedAnnotation is set on BackupRuns recorded for a Job the Backup
+	// started itself; the BackupRun controller never starts a Job for them
+	RecordedAnnotation = "gobackup.io/recorded"
+	// PrunedAnnotation is set on Jobs whose recorded BackupRun was pruned, so
+	// the run is not recorded again while the Job is kept
+	PrunedAnnotation = "gobackup.io/pruned"
+
+	// ManualJobAnnotation is set to "manual" on Jobs started outside the
+	// schedule of a CronJob, as kubectl create job --from does
+	ManualJobAnnotation = "cronjob.kubernetes.io/instantiate"
 )
 
 // +kubebuilder:rbac:groups=gobackup.io,resources=backups,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=gobackup.io,resources=backups/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=gobackup.io,resources=backups/finalizers,verbs=update
+// +kubebuilder:rbac:groups=gobackup.io,resources=backupruns,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=gobackup.io,resources=databases,verbs=get;list;watch
+// +kubebuilder:rbac:groups=gobackup.io,resources=databases/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=gobackup.io,resources=storages,verbs=get;list;watch
 // +kubebuilder:rbac:groups=gobackup.io,resources=postgresqls,verbs=get;list;watch
 // +kubebuilder:rbac:groups=gobackup.io,resources=s3s,verbs=get;list;watch
@@ -71,9 +101,12 @@ const (
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=pods/log,verbs=get
+// +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch
+// +kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch
 
 // Reconcile is the main reconciliation loop for Backup resources.
-// It handles the creation and management of CronJobs for scheduled backups.
+// It handles the creation and management of CronJobs for scheduled backups and
+// of single Jobs for unscheduled (one-shot) backups.
 // It separates create and update operations for better control and logging.
 func (r *BackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
@@ -85,10 +118,20 @@ func (r *BackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	if err := refreshJobDefaults(ctx, r.Client); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Check if Backup is being deleted
 	if !backup.DeletionTimestamp.IsZero() {
-		logger.Info("Backup is being deleted, skipping reconciliation", "name", backup.Name)
-		return ctrl.Result{}, nil
+		return r.handleBackupDeletion(ctx, backup)
+	}
+
+	if err := r.ensureCleanupFinalizer(ctx, backup); err != nil {
+		if errors.IsConflict(err) {
+			return ctrl.Result{Requeue: true}, nil
+		}
+		return ctrl.Result{}, err
 	}
 
 	// Determine if this is a create or update operation
@@ -102,9 +145,80 @@ func (r *BackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return ctrl.Result{}, err
 	}
 
+	// Invalid schedules are reported in status; the existing CronJob, if
+	// any, is left untouched until the schedule is fixed
+	scheduleValid, err := r.reconcileSchedule(ctx, backup)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Runs render the workspace size, archive claims, RDB volumes and
+	// exec-mode Databases recorded in status
+	if err := r.reconcileWorkspaceSize(ctx, backup); err != nil {
+		logger.Error(err, "Failed to reconcile workspace size")
+		return ctrl.Result{}, err
+	}
+	if err := r.reconcileArchives(ctx, backup); err != nil {
+		logger.Error(err, "Failed to reconcile archive claims")
+		return ctrl.Result{}, err
+	}
+	if err := r.reconcileRdbVolumes(ctx, backup); err != nil {
+		logger.Error(err, "Failed to reconcile RDB volumes")
+		return ctrl.Result{}, err
+	}
+	untilExecRefresh, err := r.reconcileExecDatabases(ctx, backup)
+	if err != nil {
+		logger.Error(err, "Failed to reconcile exec-mode databases")
+		return ctrl.Result{}, err
+	}
+
+	// Drop the CronJobs of schedule tiers that were removed from the spec
+	if err := r.pruneTierCronJobs(ctx, backup); err != nil {
+		logger.Error(err, "Failed to prune CronJobs of removed schedule tiers")
+		return ctrl.Result{}, err
+	}
+
+	// The CronJob of a schedule tier, <backup>-<tier>, may be the name of
+	// another Backup
+	if owner, name, err := r.findNameConflict(ctx, backup); err != nil {
+		return ctrl.Result{}, err
+	} else if owner != "" {
+		logger.Info("CronJob name is used by another Backup, not reconciling", "cronJob", name, "backup", owner)
+		r.Recorder.Eventf(backup, nil, corev1.EventTypeWarning, ReasonNameConflict, "RenderCronJob",
+			"CronJob %s is a CronJob of Backup %s; rename the Backup or the schedule tier", name, owner)
+		return ctrl.Result{RequeueAfter: NameConflictRetryInterval}, nil
+	}
+
 	// Route to appropriate handler based on operation type
 	var result ctrl.Result
-	if isCreate {
+	if len(backup.Spec.Schedules) > 0 {
+		if !scheduleValid {
+			logger.Info("Invalid schedule tier, not reconciling CronJobs", "namespace", backup.Namespace, "name", backup.Name)
+		} else {
+			var legacyCronJob *batchv1.CronJob
+			if !isCreate {
+				legacyCronJob = cronJob
+			}
+			var err error
+			result, err = r.handleTieredBackup(ctx, backup, legacyCronJob)
+			if err != nil {
+				return result, err
+			}
+		}
+	} else if !hasSchedule(backup) {
+		logger.Info("Handling one-shot Backup", "namespace", backup.Namespace, "name", backup.Name)
+		var existingCronJob *batchv1.CronJob
+		if !isCreate {
+			existingCronJob = cronJob
+		}
+		var err error
+		result, err = r.handleOneShotBackup(ctx, backup, existingCronJob)
+		if err != nil {
+			return result, err
+		}
+	} else if !scheduleValid {
+		logger.Info("Invalid schedule, not reconciling CronJob", "namespace", backup.Namespace, "name", backup.Name)
+	} else if isCreate {
 		logger.Info("Handling Backup CREATE operation", "namespace", backup.Namespace, "name", backup.Name)
 		var err error
 		result, err = r.handleBackupCreate(ctx, backup)
@@ -120,23 +234,87 @@ func (r *BackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		}
 	}
 
+	// The Secret is rendered on spec changes; repair it when edited by hand
+	if backup.Status.ObservedGeneration == backup.Generation {
+		if err := r.repairSecret(ctx, backup); err != nil {
+			logger.Error(err, "Failed to repair secret")
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Follow the Services and objects linked Databases are resolved from
+	untilRefresh, err := r.refreshLinkedConfig(ctx, backup)
+	if err != nil {
+		logger.Error(err, "Failed to refresh linked configuration")
+		return ctrl.Result{}, err
+	}
+
 	if err := r.reconcileJobStatus(ctx, backup); err != nil {
 		logger.Error(err, "Failed to reconcile job status")
 		return ctrl.Result{}, err
 	}
 
+	if err := r.reconcileTierStatus(ctx, backup); err != nil {
+		logger.Error(err, "Failed to reconcile schedule tier status")
+		return ctrl.Result{}, err
+	}
+
+	if err := r.reconcileBackupRuns(ctx, backup); err != nil {
+		logger.Error(err, "Failed to reconcile backup runs")
+		return ctrl.Result{}, err
+	}
+
+	if err := r.pruneOrphanedJobs(ctx, backup); err != nil {
+		logger.Error(err, "Failed to prune orphaned backup jobs")
+		return ctrl.Result{}, err
+	}
+
+	if err := r.reconcileDependencies(ctx, backup); err != nil {
+		logger.Error(err, "Failed to reconcile dependencies")
+		return ctrl.Result{}, err
+	}
+
+	if err := r.reconcileRetention(ctx, backup); err != nil {
+		logger.Error(err, "Failed to reconcile retention")
+		return ctrl.Result{}, err
+	}
+
+	untilStale, err := r.reconcileRPO(ctx, backup)
+	if err != nil {
+		logger.Error(err, "Failed to reconcile RPO")
+		return ctrl.Result{}, err
+	}
+	// Come back when the Backup turns stale, even if no Job shows up by then
+	if untilStale > 0 && !result.Requeue && (result.RequeueAfter == 0 || untilStale < result.RequeueAfter) {
+		result.RequeueAfter = untilStale
+	}
+	if untilRefresh > 0 && !result.Requeue && (result.RequeueAfter == 0 || untilRefresh < result.RequeueAfter) {
+		result.RequeueAfter = untilRefresh
+	}
+	if untilExecRefresh > 0 && !result.Requeue && (result.RequeueAfter == 0 || untilExecRefresh < result.RequeueAfter) {
+		result.RequeueAfter = untilExecRefresh
+	}
+
 	shouldRequeue := false
 	if backup.Status.LastRun != nil {
 		switch backup.Status.LastRun.Phase {
-		case "Running", "Pending":
+		case "Running", "Pending", "Queued", "Waiting":
 			shouldRequeue = true
 		}
 	}
 
-	if shouldRequeue && !result.Requeue && result.RequeueAfter == 0 {
+	if shouldRequeue && !result.Requeue && (result.RequeueAfter == 0 || result.RequeueAfter > 30*time.Second) {
 		result.RequeueAfter = 30 * time.Second
 	}
 
+	// Come back after the next scheduled run to refresh nextScheduledTime
+	if next := backup.Status.NextScheduledTime; scheduleValid && next != nil && !result.Requeue {
+		untilNext := time.Until(next.Time) + time.Second
+		if untilNext > 0 && (result.RequeueAfter == 0 || untilNext < result.RequeueAfter) {
+			result.RequeueAfter = untilNext
+		}
+	}
+
 	return result, nil
 }
 
@@ -146,18 +324,6 @@ func (r *BackupReconciler) handleBackupCreate(ctx context.Context, backup *backu
 	logger := log.FromContext(ctx)
 	logger.Info("Processing Backup creation", "namespace", backup.Namespace, "name", backup.Name)
 
-	// Validate that schedule is defined (we only support scheduled backups)
-	if backup.Spec.Schedule == nil || strings.TrimSpace(backup.Spec.Schedule.Cron) == "" {
-		logger.Info("Backup has no schedule defined, ignoring", "name", backup.Name)
-		return ctrl.Result{}, nil
-	}
-
-	// Validate cron expression format
-	if err := r.validateCronExpression(backup.Spec.Schedule.Cron); err != nil {
-		logger.Error(err, "Invalid cron expression during create", "cron", backup.Spec.Schedule.Cron)
-		return ctrl.Result{}, err
-	}
-
 	// Validate the backup spec
 	if err := r.validateBackupSpec(backup); err != nil {
 		logger.Error(err, "Invalid backup specification during create")
@@ -165,22 +331,28 @@ func (r *BackupReconciler) handleBackupCreate(ctx context.Context, backup *backu
 	}
 
 	// Create the secret that will be used by the CronJob
-	if err := r.K8s.CreateSecret(ctx, backup); err != nil {
+	configHash, err := r.K8s.CreateSecret(ctx, backup)
+	if err != nil {
 		logger.Error(err, "Failed to create secret for scheduled backup")
 		return ctrl.Result{}, err
 	}
 
 	// Create a new CronJob
 	logger.Info("Creating a new CronJob for Backup", "namespace", backup.Namespace, "name", backup.Name)
-	if _, err := r.createCronJob(ctx, backup); err != nil {
+	cronJob, err := r.createCronJob(ctx, backup, nil)
+	if err != nil {
 		logger.Error(err, "Failed to create CronJob during Backup create")
 		return ctrl.Result{}, err
 	}
+	if backup.Generation == backup.Status.ObservedGeneration {
+		r.Recorder.Eventf(backup, cronJob, corev1.EventTypeNormal, ReasonDriftCorrected, "RenderCronJob",
+			"CronJob %s was deleted, recreated it", cronJob.Name)
+	}
 
 	// Record the generation we just reconciled so the next reconcile (which
 	// sees the CronJob already exists and is routed as an update) does not
-	// immediately delete and recreate the freshly created CronJob.
-	if err := r.recordObservedGeneration(ctx, backup); err != nil {
+	// immediately update the freshly created CronJob.
+	if err := r.recordObservedGeneration(ctx, backup, configHash); err != nil {
 		logger.Error(err, "Failed to record observed generation after create")
 		return ctrl.Result{}, err
 	}
@@ -191,24 +363,12 @@ func (r *BackupReconciler) handleBackupCreate(ctx context.Context, backup *backu
 
 // handleBackupUpdate handles the update of an existing Backup resource.
 // This method is called when a Backup CRD is updated. On every manifest edit
-// it deletes the old CronJob, recreates it from the updated spec, and triggers
-// an immediate run if nothing is already running.
+// it updates the CronJob in place, so Jobs it already started keep their
+// owner, and starts an immediate run as requested by spec.runOnChange.
 func (r *BackupReconciler) handleBackupUpdate(ctx context.Context, backup *backupv1.Backup, existingCronJob *batchv1.CronJob) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 	logger.Info("Processing Backup update", "namespace", backup.Namespace, "name", backup.Name)
 
-	// Validate that schedule is defined (we only support scheduled backups)
-	if backup.Spec.Schedule == nil || strings.TrimSpace(backup.Spec.Schedule.Cron) == "" {
-		logger.Info("Backup has no schedule defined, ignoring update", "name", backup.Name)
-		return ctrl.Result{}, nil
-	}
-
-	// Validate cron expression format
-	if err := r.validateCronExpression(backup.Spec.Schedule.Cron); err != nil {
-		logger.Error(err, "Invalid cron expression during update", "cron", backup.Spec.Schedule.Cron)
-		return ctrl.Result{}, err
-	}
-
 	// Validate the backup spec
 	if err := r.validateBackupSpec(backup); err != nil {
 		logger.Error(err, "Invalid backup specification during update")
@@ -217,106 +377,205 @@ func (r *BackupReconciler) handleBackupUpdate(ctx context.Context, backup *backu
 
 	// Only act when the manifest actually changed. The API server bumps
 	// metadata.generation on every spec edit but not on status/metadata-only
-	// writes, so comparing it against the recorded observedGeneration prevents
-	// an endless delete/recreate loop on routine reconciles.
+	// writes, so comparing it against the recorded observedGeneration keeps
+	// routine reconciles from rewriting the CronJob and starting runs.
 	if backup.Generation == backup.Status.ObservedGeneration {
-		logger.V(1).Info("Backup spec unchanged, keeping existing CronJob", "generation", backup.Generation)
+		logger.V(1).Info("Backup spec unchanged, checking existing CronJob for drift", "generation", backup.Generation)
+		if err := r.repairCronJob(ctx, backup, existingCronJob, nil); err != nil {
+			logger.Error(err, "Failed to repair CronJob")
+			return ctrl.Result{}, err
+		}
 		return ctrl.Result{}, nil
 	}
 
-	logger.Info("Backup manifest changed, recreating CronJob",
+	logger.Info("Backup manifest changed, updating CronJob",
 		"generation", backup.Generation, "observedGeneration", backup.Status.ObservedGeneration)
 
 	// Refresh the secret consumed by the CronJob with the new configuration.
-	if err := r.K8s.CreateSecret(ctx, backup); err != nil {
+	configHash, err := r.K8s.CreateSecret(ctx, backup)
+	if err != nil {
 		logger.Error(err, "Failed to update secret for scheduled backup")
 		return ctrl.Result{}, err
 	}
 
-	// Delete the old CronJob, orphaning any Jobs/Pods it already spawned so an
-	// in-progress backup is allowed to run to completion.
-	if err := r.deleteCronJob(ctx, existingCronJob); err != nil {
-		logger.Error(err, "Failed to delete old CronJob during Backup update")
+	if err := r.updateCronJob(ctx, backup, existingCronJob, nil); err != nil {
+		logger.Error(err, "Failed to update CronJob during Backup update")
 		return ctrl.Result{}, err
 	}
 
-	// Recreate the CronJob from the updated spec.
-	newCronJob, err := r.createCronJob(ctx, backup)
-	if err != nil {
-		if errors.IsAlreadyExists(err) {
-			// The old CronJob is still terminating; retry shortly.
-			logger.V(1).Info("Old CronJob still terminating, requeueing")
-			return ctrl.Result{RequeueAfter: 2 * time.Second}, nil
+	if runOnChange(backup, configHash) {
+		// Only trigger an immediate backup when nothing is already running for
+		// this Backup, so we don't stack a run on top of an in-progress one.
+		inProgress, err := r.hasRunningBackupJob(ctx, backup)
+		if err != nil {
+			logger.Error(err, "Failed to check for in-progress backup jobs")
+			return ctrl.Result{}, err
 		}
-		logger.Error(err, "Failed to recreate CronJob during Backup update")
+		if inProgress {
+			logger.Info("A backup run is already in progress, skipping immediate run", "name", backup.Name)
+		} else {
+			if err := r.triggerManualBackupJob(ctx, backup); err != nil {
+				logger.Error(err, "Failed to trigger immediate backup run")
+				return ctrl.Result{}, err
+			}
+			logger.Info("Triggered immediate backup run after manifest change", "name", backup.Name, "runOnChange", backup.Spec.RunOnChange)
+		}
+	}
+
+	// Record the generation we just reconciled so we don't update again until
+	// the next manifest edit.
+	if err := r.recordObservedGeneration(ctx, backup, configHash); err != nil {
+		logger.Error(err, "Failed to record observed generation")
 		return ctrl.Result{}, err
 	}
 
-	// Run if needed: only trigger an immediate backup when nothing is already
-	// running for this Backup, so we don't stack a run on top of an in-progress one.
-	inProgress, err := r.hasRunningBackupJob(ctx, backup)
-	if err != nil {
-		logger.Error(err, "Failed to check for in-progress backup jobs")
+	logger.Info("Successfully updated CronJob for Backup", "namespace", backup.Namespace, "name", backup.Name)
+	return ctrl.Result{}, nil
+}
+
+// runOnChange reports whether spec.runOnChange asks for a run after the edit
+// being reconciled, given the hash of the newly rendered configuration
+func runOnChange(backup *backupv1.Backup, configHash string) bool {
+	switch backup.Spec.RunOnChange {
+	case backupv1.RunOnChangeAlways:
+		return true
+	case backupv1.RunOnChangeIfConfigChanged:
+		return configHash != backup.Status.ConfigHash
+	default:
+		return false
+	}
+}
+
+// handleOneShotBackup handles a Backup without a schedule. The first reconcile
+// renders the gobackup.yml Secret and launches a single Job. Later spec edits
+// only refresh the Secret; another Job is started when the TriggerAnnotation is
+// set to a value that has not been acted on yet.
+func (r *BackupReconciler) handleOneShotBackup(ctx context.Context, backup *backupv1.Backup, existingCronJob *batchv1.CronJob) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	// Validate the backup spec
+	if err := r.validateBackupSpec(backup); err != nil {
+		logger.Error(err, "Invalid backup specification for one-shot backup")
 		return ctrl.Result{}, err
 	}
-	if inProgress {
-		logger.Info("A backup run is already in progress, skipping immediate run", "name", backup.Name)
-	} else {
-		if err := r.triggerManualBackupJob(ctx, backup, newCronJob); err != nil {
-			logger.Error(err, "Failed to trigger immediate backup run")
+
+	// The schedule was removed from a previously scheduled Backup; drop its CronJob.
+	if existingCronJob != nil {
+		if err := r.deleteCronJob(ctx, existingCronJob); err != nil {
+			logger.Error(err, "Failed to delete CronJob of now unscheduled Backup")
 			return ctrl.Result{}, err
 		}
-		logger.Info("Triggered immediate backup run after manifest change", "name", backup.Name)
 	}
 
-	// Record the generation we just reconciled so we don't recreate again until
-	// the next manifest edit.
-	if err := r.recordObservedGeneration(ctx, backup); err != nil {
+	firstRun := backup.Status.ObservedGeneration == 0
+	specChanged := backup.Generation != backup.Status.ObservedGeneration
+	trigger := backup.Annotations[TriggerAnnotation]
+	triggered := trigger != "" && trigger != backup.Status.ObservedTrigger
+
+	if !specChanged && !triggered {
+		logger.V(1).Info("One-shot Backup unchanged and not triggered", "generation", backup.Generation)
+		return ctrl.Result{}, nil
+	}
+
+	// Refresh the secret so the next run picks up the edited configuration.
+	var configHash string
+	if specChanged {
+		var err error
+		if configHash, err = r.K8s.CreateSecret(ctx, backup); err != nil {
+			logger.Error(err, "Failed to create secret for one-shot backup")
+			return ctrl.Result{}, err
+		}
+	}
+
+	if firstRun || triggered {
+		inProgress, err := r.hasRunningBackupJob(ctx, backup)
+		if err != nil {
+			logger.Error(err, "Failed to check for in-progress backup jobs")
+			return ctrl.Result{}, err
+		}
+		if inProgress {
+			// Leave the trigger unobserved so it is picked up once the current
+			// run ends. The generation is not recorded before the initial run,
+			// which would otherwise never start.
+			logger.Info("A backup run is already in progress, deferring one-shot run", "name", backup.Name)
+			if !firstRun {
+				if err := r.recordObservedGeneration(ctx, backup, configHash); err != nil {
+					logger.Error(err, "Failed to record observed generation")
+					return ctrl.Result{}, err
+				}
+			}
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		}
+
+		if err := r.createOneShotJob(ctx, backup, trigger); err != nil {
+			logger.Error(err, "Failed to create one-shot backup Job")
+			return ctrl.Result{}, err
+		}
+		logger.Info("Started one-shot backup run", "name", backup.Name, "trigger", trigger)
+	}
+
+	if err := r.recordObservedTrigger(ctx, backup, trigger); err != nil {
+		logger.Error(err, "Failed to record observed trigger")
+		return ctrl.Result{}, err
+	}
+	if err := r.recordObservedGeneration(ctx, backup, configHash); err != nil {
 		logger.Error(err, "Failed to record observed generation")
 		return ctrl.Result{}, err
 	}
 
-	logger.Info("Successfully recreated CronJob for Backup", "namespace", backup.Namespace, "name", backup.Name)
 	return ctrl.Result{}, nil
 }
 
+// hasSchedule reports whether the Backup defines a cron schedule.
+func hasSchedule(backup *backupv1.Backup) bool {
+	return backup.Spec.Schedule != nil && strings.TrimSpace(backup.Spec.Schedule.Cron) != ""
+}
+
 // validateBackupSpec validates that the backup spec is correctly configured.
-// It ensures that at least one storage and one database reference is specified.
+// It ensures that at least one storage and one database reference or archive
+// is specified and that spec.jobTemplate applies.
 func (r *BackupReconciler) validateBackupSpec(backup *backupv1.Backup) error {
 	if len(backup.Spec.StorageRefs) == 0 {
 		return fmt.Errorf("no storage references specified in backup spec")
 	}
 
-	if len(backup.Spec.DatabaseRefs) == 0 {
-		return fmt.Errorf("no database references specified in backup spec")
+	if len(backup.Spec.DatabaseRefs) == 0 && len(backup.Spec.Archives) == 0 {
+		return fmt.Errorf("no database references or archives specified in backup spec")
 	}
 
-	return nil
-}
-
-// validateCronExpression performs basic validation on the cron expression.
-// This is a simple validation - Kubernetes CronJob will perform more thorough validation.
-func (r *BackupReconciler) validateCronExpression(cron string) error {
-	if cron == "" {
-		return fmt.Errorf("cron expression cannot be empty")
-	}
-
-	// Basic validation: cron expression should have 5 fields (minute hour day month weekday)
-	parts := strings.Fields(cron)
-	if len(parts) != 5 {
-		return fmt.Errorf("invalid cron expression: expected 5 fields, got %d", len(parts))
+	if _, err := buildJobTemplate(backup, ""); err != nil {
+		return err
 	}
 
 	return nil
 }
 
 // buildJobTemplate creates a JobTemplateSpec from the Backup spec.
-func (r *BackupReconciler) buildJobTemplate(backup *backupv1.Backup) batchv1.JobTemplateSpec {
-	imageName := os.Getenv("BACKUP_JOB_IMAGE")
-	if imageName == "" {
-		imageName = "huacnlee/gobackup:latest"
-	}
+// Jobs built from it carry the BackupLabel so every run can be traced back to
+// its Backup, whichever object created the Job. With a tier, only that tier's
+// gobackup model is performed and the Job carries the TierLabel too.
+// spec.jobTemplate is applied last.
+func buildJobTemplate(backup *backupv1.Backup, tier string) (batchv1.JobTemplateSpec, error) {
+	defaults := jobDefaults()
 	command := []string{"/bin/sh", "-c", "gobackup perform"}
+	labels := map[string]string{BackupLabel: backup.Name}
+	var annotations map[string]string
+	var suspend *bool
+	// Jobs start suspended while they wait for spec.dependsOn or, with the
+	// backup queue enabled, to be admitted
+	suspended := true
+	switch {
+	case len(backup.Spec.DependsOn) > 0:
+		annotations = map[string]string{WaitingAnnotation: "waiting for dependencies"}
+		suspend = &suspended
+	case queueEnabled():
+		annotations = map[string]string{QueuedAnnotation: "waiting for admission"}
+		suspend = &suspended
+	}
+	if tier != "" {
+		command = []string{"/bin/sh", "-c", "gobackup perform -m " + k8sutil.TierModelName(backup, tier)}
+		labels[TierLabel] = tier
+	}
 	configMountPath := "/root/.gobackup"
 
 	volumes := []corev1.Volume{
@@ -338,30 +597,61 @@ func (r *BackupReconciler) buildJobTemplate(backup *backupv1.Backup) batchv1.Job
 	}
 
 	// Set TTLSecondsAfterFinished to automatically clean up completed/failed Jobs
-	// Hardcoded to 1 second (1 second)
-	ttlSecondsAfterFinished := int32(60)
-
-	return batchv1.JobTemplateSpec{
-		Spec: batchv1.JobSpec{
-			TTLSecondsAfterFinished: &ttlSecondsAfterFinished,
-			Template: corev1.PodTemplateSpec{
-				Spec: corev1.PodSpec{
-					Containers: []corev1.Container{
-						{
-							Name:            "gobackup",
-							Image:           imageName,
-							ImagePullPolicy: corev1.PullIfNotPresent,
-							Command:         command,
-							VolumeMounts:    volumeMounts,
-						},
+	jobSpec := batchv1.JobSpec{
+		Suspend:                 suspend,
+		TTLSecondsAfterFinished: defaults.TTLSecondsAfterFinished,
+		Template: corev1.PodTemplateSpec{
+			// Backup pods are told apart from the pods of archived claims
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{BackupLabel: backup.Name},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name:            "gobackup",
+						Image:           defaults.Image,
+						ImagePullPolicy: defaults.ImagePullPolicy,
+						Command:         command,
+						VolumeMounts:    volumeMounts,
+						SecurityContext: defaults.SecurityContext,
 					},
-					Volumes:          volumes,
-					RestartPolicy:    corev1.RestartPolicyNever,
-					ImagePullSecrets: []corev1.LocalObjectReference{{Name: "regcred"}},
 				},
+				Volumes:          volumes,
+				RestartPolicy:    corev1.RestartPolicyNever,
+				ImagePullSecrets: defaults.ImagePullSecrets,
+				SecurityContext:  defaults.PodSecurityContext,
 			},
 		},
 	}
+	if defaults.Resources != nil {
+		jobSpec.Template.Spec.Containers[0].Resources = *defaults.Resources
+	}
+	applyWorkspace(&jobSpec.Template.Spec, backup)
+	applyArchives(&jobSpec.Template.Spec, backup)
+	applyRdbVolumes(&jobSpec.Template.Spec, backup)
+	applyExecDumps(&jobSpec.Template.Spec, backup)
+	applyRunPolicy(&jobSpec, backup.Spec.RunPolicy)
+
+	template := batchv1.JobTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Spec: jobSpec,
+	}
+	if err := applyJobTemplate(&template, backup.Spec.JobTemplate); err != nil {
+		return batchv1.JobTemplateSpec{}, err
+	}
+	return template, nil
+}
+
+// gobackupImage returns the image used for Jobs that run gobackup
+func gobackupImage() string {
+	imageName := os.Getenv("BACKUP_JOB_IMAGE")
+	if imageName == "" {
+		imageName = "huacnlee/gobackup:latest"
+	}
+	return imageName
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -369,35 +659,43 @@ func (r *BackupReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&backupv1.Backup{}).
 		Owns(&batchv1.CronJob{}).
+		Owns(&corev1.Secret{}).
 		Watches(&batchv1.Job{}, handler.EnqueueRequestsFromMapFunc(r.findBackupForJob)).
+		Watches(&backupv1.Backup{}, handler.EnqueueRequestsFromMapFunc(r.findDependentBackups)).
+		Watches(&backupv1.OperatorConfig{}, handler.EnqueueRequestsFromMapFunc(r.findAllBackups)).
 		Complete(r)
 }
 
-// findBackupForJob maps a Job to the Backup that owns it (via CronJob)
+// findBackupForJob maps a Job to its Backup, using the BackupLabel when present
+// and otherwise the Backup that owns it, either directly (one-shot runs) or via
+// its CronJob (scheduled runs)
 func (r *BackupReconciler) findBackupForJob(ctx context.Context, obj client.Object) []ctrl.Request {
 	job, ok := obj.(*batchv1.Job)
 	if !ok {
 		return nil
 	}
 
-	// Find the CronJob owner
-	var cronJobName string
+	// Find the Backup or CronJob owner
+	backupName := job.Labels[BackupLabel]
 	for _, ref := range job.OwnerReferences {
-		if ref.Kind == "CronJob" {
-			cronJobName = ref.Name
+		if backupName != "" {
+			break
+		}
+		if ref.Kind == "Backup" || ref.Kind == "CronJob" {
+			// The CronJob name is the same as the Backup name
+			backupName = ref.Name
 			break
 		}
 	}
 
-	if cronJobName == "" {
+	if backupName == "" {
 		return nil
 	}
 
-	// The CronJob name is the same as the Backup name
 	return []ctrl.Request{
 		{
 			NamespacedName: types.NamespacedName{
-				Name:      cronJobName,
+				Name:      backupName,
 				Namespace: job.Namespace,
 			},
 		},
@@ -406,51 +704,120 @@ func (r *BackupReconciler) findBackupForJob(ctx context.Context, obj client.Obje
 
 // createCronJob creates a CronJob for scheduled backups.
 // It sets up the job template, schedule, and other CronJob-specific configurations.
-func (r *BackupReconciler) createCronJob(ctx context.Context, backup *backupv1.Backup) (*batchv1.CronJob, error) {
+// Without a tier it creates the CronJob of spec.schedule, named after the
+// Backup; otherwise the CronJob of that entry of spec.schedules.
+func (r *BackupReconciler) createCronJob(ctx context.Context, backup *backupv1.Backup, tier *backupv1.BackupScheduleTier) (*batchv1.CronJob, error) {
 	logger := log.FromContext(ctx)
 	logger.Info("Creating CronJob for scheduled backup", "namespace", backup.Namespace, "name", backup.Name)
 
+	cronJob, err := r.buildCronJob(backup, tier)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the CronJob
+	if err := r.Create(ctx, cronJob); err != nil {
+		return nil, fmt.Errorf("failed to create CronJob: %w", err)
+	}
+
+	return cronJob, nil
+}
+
+// updateCronJob patches an existing CronJob to match the Backup spec. Jobs
+// the CronJob already started are left alone; later ones use the new spec.
+func (r *BackupReconciler) updateCronJob(ctx context.Context, backup *backupv1.Backup, existing *batchv1.CronJob, tier *backupv1.BackupScheduleTier) error {
+	desired, err := r.buildCronJob(backup, tier)
+	if err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(existing.DeepCopy())
+	if existing.Labels == nil {
+		existing.Labels = map[string]string{}
+	}
+	for k, v := range desired.Labels {
+		existing.Labels[k] = v
+	}
+	if existing.Annotations == nil {
+		existing.Annotations = map[string]string{}
+	}
+	for k, v := range desired.Annotations {
+		existing.Annotations[k] = v
+	}
+	existing.Spec = desired.Spec
+	// CronJobs created before the Backup owned them are adopted
+	if err := controllerutil.SetControllerReference(backup, existing, r.Scheme); err != nil {
+		return fmt.Errorf("failed to set controller reference for CronJob: %w", err)
+	}
+	if err := r.Patch(ctx, existing, patch); err != nil {
+		return fmt.Errorf("failed to update CronJob %s/%s: %w", existing.Namespace, existing.Name, err)
+	}
+	return nil
+}
+
+// buildCronJob builds the CronJob of a scheduled Backup, or of one of its
+// schedule tiers, owned by the Backup
+func (r *BackupReconciler) buildCronJob(backup *backupv1.Backup, tier *backupv1.BackupScheduleTier) (*batchv1.CronJob, error) {
+	schedule := backup.Spec.Schedule
+	name := backup.Name
+	tierName := ""
+	if tier != nil {
+		schedule = &tier.BackupSchedule
+		name = tierCronJobName(backup, tier.Name)
+		tierName = tier.Name
+	}
+	expression, err := effectiveCron(backup, schedule, tierName)
+	if err != nil {
+		return nil, err
+	}
+
 	// Build the job template
-	jobTemplate := r.buildJobTemplate(backup)
+	jobTemplate, err := buildJobTemplate(backup, tierName)
+	if err != nil {
+		return nil, err
+	}
 
 	// Set default values for optional fields
-	var successfulLimit int32 = 3
-	var failedLimit int32 = 1
+	defaults := jobDefaults()
+	successfulLimit := *defaults.SuccessfulJobsHistoryLimit
+	failedLimit := *defaults.FailedJobsHistoryLimit
 
-	if backup.Spec.Schedule.SuccessfulJobsHistoryLimit != nil {
-		successfulLimit = *backup.Spec.Schedule.SuccessfulJobsHistoryLimit
+	if schedule.SuccessfulJobsHistoryLimit != nil {
+		successfulLimit = *schedule.SuccessfulJobsHistoryLimit
 	}
-	if backup.Spec.Schedule.FailedJobsHistoryLimit != nil {
-		failedLimit = *backup.Spec.Schedule.FailedJobsHistoryLimit
+	if schedule.FailedJobsHistoryLimit != nil {
+		failedLimit = *schedule.FailedJobsHistoryLimit
 	}
 
 	// Create the CronJob
 	cronJob := &batchv1.CronJob{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      backup.Name,
+			Name:      name,
 			Namespace: backup.Namespace,
+			Labels:    jobTemplate.Labels,
 		},
 		Spec: batchv1.CronJobSpec{
-			Schedule:                   backup.Spec.Schedule.Cron,
+			Schedule:                   expression,
+			TimeZone:                   schedule.TimeZone,
 			JobTemplate:                jobTemplate,
 			ConcurrencyPolicy:          batchv1.ForbidConcurrent,
-			StartingDeadlineSeconds:    backup.Spec.Schedule.StartingDeadlineSeconds,
+			StartingDeadlineSeconds:    schedule.StartingDeadlineSeconds,
 			SuccessfulJobsHistoryLimit: &successfulLimit,
 			FailedJobsHistoryLimit:     &failedLimit,
-			Suspend:                    backup.Spec.Schedule.Suspend,
+			Suspend:                    schedule.Suspend,
 		},
 	}
 
+	hash, err := cronJobTemplateHash(cronJob)
+	if err != nil {
+		return nil, err
+	}
+	cronJob.Annotations = mergeMetadata(templateAnnotations(backup), map[string]string{TemplateHashAnnotation: hash})
+
 	// Set the Backup instance as the owner of the CronJob
 	if err := controllerutil.SetControllerReference(backup, cronJob, r.Scheme); err != nil {
 		return nil, fmt.Errorf("failed to set controller reference for CronJob: %w", err)
 	}
-
-	// Create the CronJob
-	if err := r.Create(ctx, cronJob); err != nil {
-		return nil, fmt.Errorf("failed to create CronJob: %w", err)
-	}
-
 	return cronJob, nil
 }
 
@@ -470,47 +837,103 @@ func (r *BackupReconciler) deleteCronJob(ctx context.Context, cronJob *batchv1.C
 }
 
 // hasRunningBackupJob reports whether a Job belonging to this Backup is still
-// Pending or Running. Jobs created by the CronJob are name-prefixed with the
-// Backup name, matching the convention used by reconcileJobStatus.
+// Waiting, Queued, Pending or Running.
 func (r *BackupReconciler) hasRunningBackupJob(ctx context.Context, backup *backupv1.Backup) (bool, error) {
+	jobs, err := r.listBackupJobs(ctx, backup)
+	if err != nil {
+		return false, err
+	}
+
+	for i := range jobs {
+		switch getJobPhase(&jobs[i]) {
+		case "Running", "Pending", "Queued", "Waiting":
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// listBackupJobs returns the Jobs that belong to this Backup. Jobs carry the
+// BackupLabel; Jobs created before the label was introduced are matched by
+// isLegacyBackupJob.
+func (r *BackupReconciler) listBackupJobs(ctx context.Context, backup *backupv1.Backup) ([]batchv1.Job, error) {
 	jobList := &batchv1.JobList{}
 	if err := r.List(ctx, jobList, client.InNamespace(backup.Namespace)); err != nil {
-		return false, fmt.Errorf("failed to list jobs: %w", err)
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
 	}
 
+	var jobs []batchv1.Job
 	for i := range jobList.Items {
-		job := &jobList.Items[i]
-		if !strings.HasPrefix(job.Name, backup.Name+"-") {
-			continue
-		}
-		switch r.getJobPhase(job) {
-		case "Running", "Pending":
-			return true, nil
+		if jobBelongsToBackup(&jobList.Items[i], backup) {
+			jobs = append(jobs, jobList.Items[i])
 		}
 	}
-	return false, nil
+	return jobs, nil
+}
+
+// isLegacyBackupJob reports whether an unlabelled Job is one that operator
+// versions before the BackupLabel created for the Backup: a Job of its
+// CronJob named <backup>-<scheduled time>, or a manual Job matched by
+// isLegacyManualJob. The Job must still be controlled by that CronJob or by
+// the Backup, or have lost its owner.
+func isLegacyBackupJob(job *batchv1.Job, backup *backupv1.Backup) bool {
+	timestamp, ok := strings.CutPrefix(job.Name, backup.Name+"-")
+	if !(ok && isDigits(timestamp)) && !isLegacyManualJob(job, backup) {
+		return false
+	}
+
+	owner := metav1.GetControllerOf(job)
+	switch {
+	case owner == nil:
+		return true
+	case owner.Kind == "CronJob" && owner.Name == backup.Name:
+		return true
+	default:
+		return owner.UID == backup.UID
+	}
+}
+
+// isLegacyManualJob reports whether an unlabelled Job is a manual Job that
+// operator versions before the BackupLabel created for the Backup, named
+// <backup>-manual-<unix time> and annotated as manual
+func isLegacyManualJob(job *batchv1.Job, backup *backupv1.Backup) bool {
+	timestamp, ok := strings.CutPrefix(job.Name, backup.Name+"-manual-")
+	return ok && isDigits(timestamp) && job.Annotations[ManualJobAnnotation] == "manual"
+}
+
+// isDigits reports whether s is a non-empty string of decimal digits
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
 }
 
 // triggerManualBackupJob creates a one-off Job from the Backup's job template so
 // the updated configuration takes effect immediately instead of waiting for the
-// next scheduled cron tick. The Job is owned by the freshly created CronJob so
-// findBackupForJob re-enqueues the Backup and reconcileJobStatus tracks it via
-// the shared <backup-name>- name prefix.
-func (r *BackupReconciler) triggerManualBackupJob(ctx context.Context, backup *backupv1.Backup, cronJob *batchv1.CronJob) error {
-	jobTemplate := r.buildJobTemplate(backup)
+// next scheduled cron tick. The Job is owned by the Backup so findBackupForJob
+// re-enqueues it and it is garbage collected with it, and reconcileJobStatus
+// tracks it via the BackupLabel.
+func (r *BackupReconciler) triggerManualBackupJob(ctx context.Context, backup *backupv1.Backup) error {
+	jobTemplate, err := buildJobTemplate(backup, "")
+	if err != nil {
+		return err
+	}
+	annotations := map[string]string{
+		ManualJobAnnotation: "manual",
+	}
+	for k, v := range jobTemplate.Annotations {
+		annotations[k] = v
+	}
 
 	job := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("%s-manual-%d", backup.Name, time.Now().Unix()),
-			Namespace: backup.Namespace,
-			Annotations: map[string]string{
-				"cronjob.kubernetes.io/instantiate": "manual",
-			},
+			Name:        fmt.Sprintf("%s-manual-%d", backup.Name, time.Now().Unix()),
+			Namespace:   backup.Namespace,
+			Labels:      jobTemplate.Labels,
+			Annotations: annotations,
 		},
 		Spec: jobTemplate.Spec,
 	}
 
-	if err := controllerutil.SetControllerReference(cronJob, job, r.Scheme); err != nil {
+	if err := controllerutil.SetControllerReference(backup, job, r.Scheme); err != nil {
 		return fmt.Errorf("failed to set controller reference for manual Job: %w", err)
 	}
 
@@ -520,16 +943,72 @@ func (r *BackupReconciler) triggerManualBackupJob(ctx context.Context, backup *b
 	return nil
 }
 
+// createOneShotJob creates the Job for a run of an unscheduled Backup. The Job is
+// owned by the Backup itself so findBackupForJob re-enqueues it. Its name is
+// derived from the Backup UID and the trigger value, so a reconcile retried
+// after the Job was created, for instance because the status patch failed,
+// finds it instead of starting another run.
+func (r *BackupReconciler) createOneShotJob(ctx context.Context, backup *backupv1.Backup, trigger string) error {
+	jobTemplate, err := buildJobTemplate(backup, "")
+	if err != nil {
+		return err
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        oneShotJobName(backup, trigger),
+			Namespace:   backup.Namespace,
+			Labels:      jobTemplate.Labels,
+			Annotations: jobTemplate.Annotations,
+		},
+		Spec: jobTemplate.Spec,
+	}
+
+	if err := controllerutil.SetControllerReference(backup, job, r.Scheme); err != nil {
+		return fmt.Errorf("failed to set controller reference for one-shot Job: %w", err)
+	}
+
+	if err := r.Create(ctx, job); err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create one-shot backup Job: %w", err)
+	}
+	return nil
+}
+
+// oneShotJobName returns the name of the Job started for a trigger value of
+// an unscheduled Backup, "" being its initial run
+func oneShotJobName(backup *backupv1.Backup, trigger string) string {
+	sum := sha256.Sum256([]byte(string(backup.UID) + "/" + trigger))
+	return fmt.Sprintf("%s-oneshot-%s", backup.Name, hex.EncodeToString(sum[:5]))
+}
+
+// recordObservedTrigger persists the TriggerAnnotation value that was acted on,
+// so the same value does not start another one-shot run.
+func (r *BackupReconciler) recordObservedTrigger(ctx context.Context, backup *backupv1.Backup, trigger string) error {
+	if backup.Status.ObservedTrigger == trigger {
+		return nil
+	}
+	patch := client.MergeFrom(backup.DeepCopy())
+	backup.Status.ObservedTrigger = trigger
+	if err := r.Status().Patch(ctx, backup, patch); err != nil {
+		return fmt.Errorf("failed to record observedTrigger: %w", err)
+	}
+	return nil
+}
+
 // recordObservedGeneration persists the current spec generation into the Backup
-// status so subsequent reconciles can tell whether the manifest changed. A merge
-// patch is used so it does not conflict with the status update performed later
-// in reconcileJobStatus within the same reconcile.
-func (r *BackupReconciler) recordObservedGeneration(ctx context.Context, backup *backupv1.Backup) error {
+// status so subsequent reconciles can tell whether the manifest changed,
+// together with the hash of the configuration rendered for it, unless empty.
+// A merge patch is used so it does not conflict with the status update
+// performed later in reconcileJobStatus within the same reconcile.
+func (r *BackupReconciler) recordObservedGeneration(ctx context.Context, backup *backupv1.Backup, configHash string) error {
 	if backup.Status.ObservedGeneration == backup.Generation {
 		return nil
 	}
 	patch := client.MergeFrom(backup.DeepCopy())
 	backup.Status.ObservedGeneration = backup.Generation
+	if configHash != "" {
+		backup.Status.ConfigHash = configHash
+	}
 	if err := r.Status().Patch(ctx, backup, patch); err != nil {
 		return fmt.Errorf("failed to record observedGeneration: %w", err)
 	}
@@ -541,22 +1020,13 @@ func (r *BackupReconciler) reconcileJobStatus(ctx context.Context, backup *backu
 	logger := log.FromContext(ctx)
 	logger.Info("Reconciling job status", "backup", backup.Name)
 
-	// List all Jobs owned by the CronJob (which is owned by the Backup)
-	jobList := &batchv1.JobList{}
-	if err := r.List(ctx, jobList, client.InNamespace(backup.Namespace)); err != nil {
-		return fmt.Errorf("failed to list jobs: %w", err)
+	// List all Jobs that belong to this Backup
+	relatedJobs, err := r.listBackupJobs(ctx, backup)
+	if err != nil {
+		return err
 	}
-
-	logger.Info("Found jobs in namespace", "count", len(jobList.Items), "namespace", backup.Namespace)
-
-	// Filter jobs that belong to this backup's CronJob
-	var relatedJobs []batchv1.Job
-	for _, job := range jobList.Items {
-		// Jobs created by CronJob have the CronJob name as a prefix
-		if strings.HasPrefix(job.Name, backup.Name+"-") {
-			relatedJobs = append(relatedJobs, job)
-			logger.V(1).Info("Found related job", "job", job.Name, "phase", r.getJobPhase(&job))
-		}
+	for i := range relatedJobs {
+		logger.V(1).Info("Found related job", "job", relatedJobs[i].Name, "phase", getJobPhase(&relatedJobs[i]))
 	}
 
 	logger.Info("Found related jobs for backup", "backup", backup.Name, "count", len(relatedJobs))
@@ -579,10 +1049,10 @@ func (r *BackupReconciler) reconcileJobStatus(ctx context.Context, backup *backu
 		return nil
 	}
 
-	logger.Info("Latest job found", "job", latestJob.Name, "phase", r.getJobPhase(latestJob))
+	logger.Info("Latest job found", "job", latestJob.Name, "phase", getJobPhase(latestJob))
 
 	// Get the current phase
-	currentPhase := r.getJobPhase(latestJob)
+	currentPhase := getJobPhase(latestJob)
 
 	// Check if this job has already been processed with the same phase
 	if backup.Status.LastRun != nil && backup.Status.LastRun.JobName == latestJob.Name {
@@ -647,13 +1117,145 @@ func (r *BackupReconciler) reconcileJobStatus(ctx context.Context, backup *backu
 	return nil
 }
 
+// reconcileBackupRuns records a BackupRun for every Job of this Backup that was
+// not started by one (CronJob, one-shot and manual runs), and prunes finished
+// BackupRuns beyond spec.runHistoryLimit.
+func (r *BackupReconciler) reconcileBackupRuns(ctx context.Context, backup *backupv1.Backup) error {
+	logger := log.FromContext(ctx)
+
+	jobs, err := r.listBackupJobs(ctx, backup)
+	if err != nil {
+		return err
+	}
+
+	for i := range jobs {
+		job := &jobs[i]
+		if _, ok := job.Labels[BackupRunLabel]; ok {
+			continue
+		}
+		if _, ok := job.Annotations[PrunedAnnotation]; ok {
+			continue
+		}
+
+		run := &backupv1.BackupRun{}
+		err := r.Get(ctx, types.NamespacedName{Name: job.Name, Namespace: job.Namespace}, run)
+		if err == nil {
+			continue
+		}
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get BackupRun %s: %w", job.Name, err)
+		}
+
+		// The BackupRun shares the Job's name; the BackupRun controller adopts
+		// the existing Job instead of starting a new one.
+		run = &backupv1.BackupRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        job.Name,
+				Namespace:   job.Namespace,
+				Labels:      map[string]string{BackupLabel: backup.Name},
+				Annotations: map[string]string{RecordedAnnotation: "true"},
+			},
+			Spec: backupv1.BackupRunSpec{
+				BackupRef: corev1.LocalObjectReference{Name: backup.Name},
+				Tier:      job.Labels[TierLabel],
+			},
+		}
+		if err := controllerutil.SetOwnerReference(backup, run, r.Scheme); err != nil {
+			return fmt.Errorf("failed to set owner reference for BackupRun: %w", err)
+		}
+		if err := r.Create(ctx, run); err != nil && !errors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create BackupRun for job %s: %w", job.Name, err)
+		}
+		logger.Info("Recorded BackupRun for job", "job", job.Name)
+	}
+
+	if backup.Spec.RunHistoryLimit == nil {
+		return nil
+	}
+	return r.pruneBackupRuns(ctx, backup, int(*backup.Spec.RunHistoryLimit))
+}
+
+// pruneBackupRuns deletes the oldest finished BackupRuns of this Backup so that
+// at most limit of them remain. Pending and Running runs are never pruned. The
+// Jobs of pruned recorded runs are marked so they are not recorded again.
+func (r *BackupReconciler) pruneBackupRuns(ctx context.Context, backup *backupv1.Backup, limit int) error {
+	runList := &backupv1.BackupRunList{}
+	if err := r.List(ctx, runList, client.InNamespace(backup.Namespace)); err != nil {
+		return fmt.Errorf("failed to list backup runs: %w", err)
+	}
+
+	var finished []*backupv1.BackupRun
+	for i := range runList.Items {
+		run := &runList.Items[i]
+		if run.Spec.BackupRef.Name != backup.Name {
+			continue
+		}
+		if run.Status.Phase == "Succeeded" || run.Status.Phase == "Failed" {
+			finished = append(finished, run)
+		}
+	}
+	if len(finished) <= limit {
+		return nil
+	}
+
+	// Newest first, so everything past the limit is the oldest history
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[j].CreationTimestamp.Before(&finished[i].CreationTimestamp)
+	})
+	for _, run := range finished[limit:] {
+		if _, ok := run.Annotations[RecordedAnnotation]; ok {
+			if err := r.markJobPruned(ctx, run); err != nil {
+				return err
+			}
+		}
+		if err := r.Delete(ctx, run); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to prune BackupRun %s: %w", run.Name, err)
+		}
+	}
+	return nil
+}
+
+// markJobPruned sets PrunedAnnotation on the Job a recorded BackupRun shares
+// its name with, if the Job still exists
+func (r *BackupReconciler) markJobPruned(ctx context.Context, run *backupv1.BackupRun) error {
+	job := &batchv1.Job{}
+	if err := r.Get(ctx, types.NamespacedName{Name: run.Name, Namespace: run.Namespace}, job); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get Job %s: %w", run.Name, err)
+	}
+	if _, ok := job.Annotations[PrunedAnnotation]; ok {
+		return nil
+	}
+	orig := job.DeepCopy()
+	if job.Annotations == nil {
+		job.Annotations = map[string]string{}
+	}
+	job.Annotations[PrunedAnnotation] = "true"
+	if err := r.Patch(ctx, job, client.MergeFrom(orig)); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to mark Job %s as pruned: %w", job.Name, err)
+	}
+	return nil
+}
+
 // getJobPhase returns the phase of a Job
-func (r *BackupReconciler) getJobPhase(job *batchv1.Job) string {
+func getJobPhase(job *batchv1.Job) string {
 	if job.Status.Succeeded > 0 {
 		return "Succeeded"
 	}
-	if job.Status.Failed > 0 {
-		return "Failed"
+	// Failed pods are retried up to the backoff limit; only the Job's Failed
+	// condition ends the run
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return "Failed"
+		}
+	}
+	if isWaiting(job) {
+		return "Waiting"
+	}
+	if isQueued(job) {
+		return "Queued"
 	}
 	if job.Status.Active > 0 {
 		return "Running"
@@ -661,20 +1263,46 @@ func (r *BackupReconciler) getJobPhase(job *batchv1.Job) string {
 	return "Pending"
 }
 
-// buildRunStatus creates a BackupRunStatus from a Job
+// buildRunStatus creates a BackupRunStatus from a Job for the Backup status
 func (r *BackupReconciler) buildRunStatus(ctx context.Context, job *batchv1.Job) backupv1.BackupRunStatus {
 	logger := log.FromContext(ctx)
 
+	runStatus := jobRunStatus(job)
+	classifyRun(ctx, r.Client, job, &runStatus)
+
+	// Collect logs only on failure to save space
+	if runStatus.Phase == "Failed" && r.Clientset != nil {
+		logs, err := collectPodLogs(ctx, r.Client, r.Clientset, job, "gobackup")
+		if err != nil {
+			logger.V(1).Info("Failed to collect pod logs", "error", err)
+			runStatus.Logs = truncateString(fmt.Sprintf("Failed to collect logs: %v", err), MaxLogSize)
+		} else {
+			runStatus.Logs = logs
+		}
+	}
+
+	return runStatus
+}
+
+// jobRunStatus derives the phase, timing and message of a run from its Job
+func jobRunStatus(job *batchv1.Job) backupv1.BackupRunStatus {
 	runStatus := backupv1.BackupRunStatus{
 		JobName:   job.Name,
 		StartTime: job.Status.StartTime,
-		Phase:     r.getJobPhase(job),
+		Phase:     getJobPhase(job),
 	}
 
 	if job.Status.CompletionTime != nil {
 		runStatus.CompletionTime = job.Status.CompletionTime
 	}
 
+	switch runStatus.Phase {
+	case "Waiting":
+		runStatus.Message = truncateString("Waiting: "+job.Annotations[WaitingAnnotation], MaxMessageSize)
+	case "Queued":
+		runStatus.Message = truncateString("Queued: "+job.Annotations[QueuedAnnotation], MaxMessageSize)
+	}
+
 	// Set message based on job conditions
 	for _, condition := range job.Status.Conditions {
 		if condition.Type == batchv1.JobComplete && condition.Status == corev1.ConditionTrue {
@@ -684,29 +1312,18 @@ func (r *BackupReconciler) buildRunStatus(ctx context.Context, job *batchv1.Job)
 		}
 	}
 
-	// Collect logs only on failure to save space
-	if runStatus.Phase == "Failed" && r.Clientset != nil {
-		logs, err := r.collectPodLogs(ctx, job)
-		if err != nil {
-			logger.V(1).Info("Failed to collect pod logs", "error", err)
-			runStatus.Logs = truncateString(fmt.Sprintf("Failed to collect logs: %v", err), MaxLogSize)
-		} else {
-			runStatus.Logs = logs
-		}
-	}
-
 	return runStatus
 }
 
-// collectPodLogs collects logs from pods belonging to a Job
-func (r *BackupReconciler) collectPodLogs(ctx context.Context, job *batchv1.Job) (string, error) {
-	if r.Clientset == nil {
+// collectPodLogs collects logs of a container from pods belonging to a Job
+func collectPodLogs(ctx context.Context, c client.Reader, clientset *kubernetes.Clientset, job *batchv1.Job, container string) (string, error) {
+	if clientset == nil {
 		return "", fmt.Errorf("clientset not available")
 	}
 
 	// List pods for this job
 	podList := &corev1.PodList{}
-	if err := r.List(ctx, podList,
+	if err := c.List(ctx, podList,
 		client.InNamespace(job.Namespace),
 		client.MatchingLabels{"job-name": job.Name}); err != nil {
 		return "", fmt.Errorf("failed to list pods: %w", err)
@@ -720,13 +1337,12 @@ func (r *BackupReconciler) collectPodLogs(ctx context.Context, job *batchv1.Job)
 	pod := &podList.Items[0]
 
 	// Get container logs
-	tailLines := int64(100) // Last 100 lines
 	podLogOpts := &corev1.PodLogOptions{
-		Container: "gobackup",
-		TailLines: &tailLines,
+		Container: container,
+		TailLines: jobDefaults().LogTailLines,
 	}
 
-	req := r.Clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, podLogOpts)
+	req := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, podLogOpts)
 	podLogs, err := req.Stream(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to get pod logs: %w", err)
@@ -0,0 +1,73 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+)
+
+func TestCreateOneShotJob(t *testing.T) {
+	tests := []struct {
+		name     string
+		triggers []string
+		uids     []types.UID
+		jobs     int
+	}{
+		{name: "initial run", triggers: []string{""}, uids: []types.UID{"a"}, jobs: 1},
+		{name: "retried initial run", triggers: []string{"", ""}, uids: []types.UID{"a", "a"}, jobs: 1},
+		{name: "retried trigger", triggers: []string{"1718413200", "1718413200"}, uids: []types.UID{"a", "a"}, jobs: 1},
+		{name: "triggers in the same second", triggers: []string{"1718413200", "1718413200-2"}, uids: []types.UID{"a", "a"}, jobs: 2},
+		{name: "initial run then trigger", triggers: []string{"", "1718413200"}, uids: []types.UID{"a", "a"}, jobs: 2},
+		{name: "recreated backup", triggers: []string{"", ""}, uids: []types.UID{"a", "b"}, jobs: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t)
+			r := &BackupReconciler{Client: c, Scheme: c.Scheme()}
+
+			for i, trigger := range tt.triggers {
+				backup := &backupv1.Backup{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: tt.uids[i]}}
+				if err := r.createOneShotJob(context.Background(), backup, trigger); err != nil {
+					t.Fatalf("createOneShotJob(%q) failed: %v", trigger, err)
+				}
+			}
+
+			jobs := &batchv1.JobList{}
+			if err := c.List(context.Background(), jobs); err != nil {
+				t.Fatalf("failed to list jobs: %v", err)
+			}
+			if len(jobs.Items) != tt.jobs {
+				t.Errorf("got %d jobs, want %d", len(jobs.Items), tt.jobs)
+			}
+			for _, job := range jobs.Items {
+				if job.Labels[BackupLabel] != "app" || !slices.ContainsFunc(job.OwnerReferences, func(ref metav1.OwnerReference) bool {
+					return ref.Kind == "Backup" && ref.Name == "app"
+				}) {
+					t.Errorf("job %s is not labelled and owned by its Backup", job.Name)
+				}
+			}
+		})
+	}
+}
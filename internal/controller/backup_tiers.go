@@ -0,0 +1,267 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+)
+
+const (
+	// ReasonNameConflict is the Event reason for a Backup whose CronJob name
+	// is used by an older Backup
+	ReasonNameConflict = "NameConflict"
+
+	// NameConflictRetryInterval is how often a Backup with a CronJob name
+	// conflict checks whether it was resolved
+	NameConflictRetryInterval = time.Minute
+)
+
+// handleTieredBackup handles a Backup with spec.schedules. Every tier gets its
+// own CronJob, named <backup>-<tier>, performing only the tier's gobackup
+// model. Like handleBackupUpdate, CronJobs are updated in place on manifest
+// edits; unlike it, no immediate run is triggered, as that would run every
+// tier at once.
+func (r *BackupReconciler) handleTieredBackup(ctx context.Context, backup *backupv1.Backup, legacyCronJob *batchv1.CronJob) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	// Validate the backup spec
+	if err := r.validateBackupSpec(backup); err != nil {
+		logger.Error(err, "Invalid backup specification for tiered backup")
+		return ctrl.Result{}, err
+	}
+
+	// spec.schedule was replaced by spec.schedules; drop its CronJob.
+	if legacyCronJob != nil {
+		if err := r.deleteCronJob(ctx, legacyCronJob); err != nil {
+			logger.Error(err, "Failed to delete CronJob of spec.schedule")
+			return ctrl.Result{}, err
+		}
+	}
+
+	specChanged := backup.Generation != backup.Status.ObservedGeneration
+	var configHash string
+	if specChanged {
+		var err error
+		if configHash, err = r.K8s.CreateSecret(ctx, backup); err != nil {
+			logger.Error(err, "Failed to create secret for tiered backup")
+			return ctrl.Result{}, err
+		}
+	}
+
+	for i := range backup.Spec.Schedules {
+		tier := &backup.Spec.Schedules[i]
+		name := tierCronJobName(backup, tier.Name)
+
+		existing := &batchv1.CronJob{}
+		err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: backup.Namespace}, existing)
+		if err != nil && !errors.IsNotFound(err) {
+			return ctrl.Result{}, fmt.Errorf("failed to get CronJob %s: %w", name, err)
+		}
+		if err == nil {
+			if !specChanged {
+				if err := r.repairCronJob(ctx, backup, existing, tier); err != nil {
+					logger.Error(err, "Failed to repair CronJob of schedule tier", "tier", tier.Name)
+					return ctrl.Result{}, err
+				}
+				continue
+			}
+			if err := r.updateCronJob(ctx, backup, existing, tier); err != nil {
+				logger.Error(err, "Failed to update CronJob of schedule tier", "tier", tier.Name)
+				return ctrl.Result{}, err
+			}
+			logger.Info("Updated CronJob for schedule tier", "name", name, "tier", tier.Name)
+			continue
+		}
+
+		cronJob, err := r.createCronJob(ctx, backup, tier)
+		if err != nil {
+			logger.Error(err, "Failed to create CronJob of schedule tier", "tier", tier.Name)
+			return ctrl.Result{}, err
+		}
+		logger.Info("Created CronJob for schedule tier", "name", name, "tier", tier.Name)
+		if !specChanged && findTierStatus(&backup.Status, tier.Name) != nil {
+			r.Recorder.Eventf(backup, cronJob, corev1.EventTypeNormal, ReasonDriftCorrected, "RenderCronJob",
+				"CronJob %s was deleted, recreated it", name)
+		}
+	}
+
+	if err := r.recordObservedGeneration(ctx, backup, configHash); err != nil {
+		logger.Error(err, "Failed to record observed generation")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// pruneTierCronJobs deletes the CronJobs of schedule tiers that are no longer
+// in spec.schedules, including all of them once the Backup has no tiers.
+func (r *BackupReconciler) pruneTierCronJobs(ctx context.Context, backup *backupv1.Backup) error {
+	cronJobs := &batchv1.CronJobList{}
+	if err := r.List(ctx, cronJobs, client.InNamespace(backup.Namespace),
+		client.MatchingLabels{BackupLabel: backup.Name}, client.HasLabels{TierLabel}); err != nil {
+		return fmt.Errorf("failed to list CronJobs of schedule tiers: %w", err)
+	}
+
+	for i := range cronJobs.Items {
+		cronJob := &cronJobs.Items[i]
+		if findScheduleTier(backup, cronJob.Labels[TierLabel]) != nil {
+			continue
+		}
+		if err := r.deleteCronJob(ctx, cronJob); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileTierStatus records the latest run of every schedule tier in
+// status.tiers. Tier entries themselves are maintained by reconcileSchedule.
+func (r *BackupReconciler) reconcileTierStatus(ctx context.Context, backup *backupv1.Backup) error {
+	if len(backup.Status.Tiers) == 0 {
+		return nil
+	}
+
+	jobs, err := r.listBackupJobs(ctx, backup)
+	if err != nil {
+		return err
+	}
+
+	latest := map[string]*batchv1.Job{}
+	for i := range jobs {
+		job := &jobs[i]
+		tier := job.Labels[TierLabel]
+		if tier == "" {
+			continue
+		}
+		if current := latest[tier]; current == nil || job.CreationTimestamp.After(current.CreationTimestamp.Time) {
+			latest[tier] = job
+		}
+	}
+
+	original := backup.DeepCopy()
+	for i := range backup.Status.Tiers {
+		tierStatus := &backup.Status.Tiers[i]
+		job := latest[tierStatus.Name]
+		if job == nil {
+			continue
+		}
+
+		phase := getJobPhase(job)
+		if tierStatus.LastRun != nil && tierStatus.LastRun.JobName == job.Name && tierStatus.LastRun.Phase == phase {
+			continue
+		}
+
+		runStatus := r.buildRunStatus(ctx, job)
+		tierStatus.LastRun = &runStatus
+		if phase == "Succeeded" {
+			completed := metav1.Now()
+			if runStatus.CompletionTime != nil {
+				completed = *runStatus.CompletionTime
+			}
+			tierStatus.LastSuccessfulBackupTime = &completed
+		}
+	}
+
+	if equality.Semantic.DeepEqual(original.Status, backup.Status) {
+		return nil
+	}
+	if err := r.Status().Patch(ctx, backup, client.MergeFrom(original)); err != nil {
+		return fmt.Errorf("failed to update schedule tier status: %w", err)
+	}
+	return nil
+}
+
+// cronJobNames returns the names of the CronJobs a Backup may create or
+// delete: its own name, and that of the CronJob of every schedule tier
+func cronJobNames(backup *backupv1.Backup) []string {
+	names := []string{backup.Name}
+	for _, tier := range backup.Spec.Schedules {
+		names = append(names, tierCronJobName(backup, tier.Name))
+	}
+	return names
+}
+
+// findNameConflict returns the Backup created before this one that uses one
+// of its CronJob names, such as Backup db-daily and the daily tier of Backup
+// db, together with the name. The older Backup keeps the name; the newer one
+// is not reconciled until one of them is renamed.
+func (r *BackupReconciler) findNameConflict(ctx context.Context, backup *backupv1.Backup) (string, string, error) {
+	backups := &backupv1.BackupList{}
+	if err := r.List(ctx, backups, client.InNamespace(backup.Namespace)); err != nil {
+		return "", "", fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	names := cronJobNames(backup)
+	for i := range backups.Items {
+		other := &backups.Items[i]
+		if other.Name == backup.Name {
+			continue
+		}
+		older := other.CreationTimestamp.Before(&backup.CreationTimestamp) ||
+			(other.CreationTimestamp.Equal(&backup.CreationTimestamp) && other.Name < backup.Name)
+		if !older {
+			continue
+		}
+		for _, name := range cronJobNames(other) {
+			if slices.Contains(names, name) {
+				return other.Name, name, nil
+			}
+		}
+	}
+	return "", "", nil
+}
+
+// tierCronJobName returns the name of the CronJob of a schedule tier
+func tierCronJobName(backup *backupv1.Backup, tier string) string {
+	return backup.Name + "-" + tier
+}
+
+// tierDirs returns the schedule tiers of a Backup that upload to a storage,
+// which are also the directories under the storage path holding their archives
+func tierDirs(backup *backupv1.Backup, storageName string) []string {
+	var dirs []string
+	for _, tier := range backup.Spec.Schedules {
+		if len(tier.StorageRefs) == 0 || slices.Contains(tier.StorageRefs, storageName) {
+			dirs = append(dirs, tier.Name)
+		}
+	}
+	return dirs
+}
+
+// findScheduleTier returns the named entry of spec.schedules, or nil
+func findScheduleTier(backup *backupv1.Backup, name string) *backupv1.BackupScheduleTier {
+	for i := range backup.Spec.Schedules {
+		if backup.Spec.Schedules[i].Name == name {
+			return &backup.Spec.Schedules[i]
+		}
+	}
+	return nil
+}
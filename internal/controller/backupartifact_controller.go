@@ -0,0 +1,289 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+	"github.com/gobackup/gobackup-operator/pkg/k8sutil"
+	"github.com/gobackup/gobackup-operator/pkg/storage"
+)
+
+const (
+	// StorageLabel is set on BackupArtifacts to the name of their Storage
+	StorageLabel = "gobackup.io/storage"
+
+	// ArtifactSyncInterval is how often the objects in a Storage are listed
+	ArtifactSyncInterval = 5 * time.Minute
+)
+
+// invalidNameChars matches characters not allowed in object names
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+// BackupArtifactReconciler keeps the BackupArtifacts of a Storage in sync with
+// the archives actually present in it
+type BackupArtifactReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	K8s    *k8sutil.K8s
+}
+
+// +kubebuilder:rbac:groups=gobackup.io,resources=backupartifacts,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gobackup.io,resources=storages,verbs=get;list;watch
+
+// Reconcile lists the archives under the Storage path and creates, updates or
+// deletes BackupArtifacts to match. Storages are listed again every
+// ArtifactSyncInterval and whenever a BackupRun uploading to them succeeds.
+func (r *BackupArtifactReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	store := &backupv1.Storage{}
+	if err := r.Get(ctx, req.NamespacedName, store); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !store.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	storageType := strings.ToLower(store.Spec.Type)
+	if !storage.IsSupported(storageType) {
+		logger.V(1).Info("Skipping artifact inventory for unsupported storage type", "type", storageType)
+		return ctrl.Result{}, nil
+	}
+
+	_, config, err := r.K8s.ResolveStorage(ctx, store.Namespace, store.Name)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to resolve storage %s: %w", store.Name, err)
+	}
+	dirs, err := r.tierDirs(ctx, store)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	lister, err := storage.NewWithDirs(storageType, config, dirs)
+	if err != nil {
+		logger.Error(err, "Invalid storage configuration for artifact inventory")
+		return ctrl.Result{}, nil
+	}
+
+	objects, err := lister.List(ctx)
+	if err != nil {
+		// Unreachable storages are retried on the regular interval rather
+		// than with backoff, to avoid hammering them
+		logger.Error(err, "Failed to list storage", "storage", store.Name)
+		return ctrl.Result{RequeueAfter: ArtifactSyncInterval}, nil
+	}
+
+	owners, err := artifactOwners(ctx, r.Client, store.Namespace, store.Name)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	existing := &backupv1.BackupArtifactList{}
+	if err := r.List(ctx, existing, client.InNamespace(store.Namespace), client.MatchingLabels{StorageLabel: store.Name}); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list backup artifacts: %w", err)
+	}
+	stale := map[string]*backupv1.BackupArtifact{}
+	for i := range existing.Items {
+		stale[existing.Items[i].Name] = &existing.Items[i]
+	}
+
+	for _, object := range objects {
+		if !isArtifactKey(object.Key) {
+			continue
+		}
+		name := artifactObjectName(store.Name, object.Key)
+		delete(stale, name)
+		if err := r.applyArtifact(ctx, store, object, name, owners.backupFor(object.Key)); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	for _, artifact := range stale {
+		if err := r.Delete(ctx, artifact); client.IgnoreNotFound(err) != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to delete backup artifact %s: %w", artifact.Name, err)
+		}
+		logger.Info("Removed BackupArtifact no longer in storage", "artifact", artifact.Name)
+	}
+
+	return ctrl.Result{RequeueAfter: ArtifactSyncInterval}, nil
+}
+
+// applyArtifact creates the BackupArtifact for a listed object, or updates it
+// when the object changed
+func (r *BackupArtifactReconciler) applyArtifact(ctx context.Context, store *backupv1.Storage, object storage.Object, name, backupName string) error {
+	artifact := &backupv1.BackupArtifact{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: store.Namespace,
+		},
+	}
+	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, artifact, func() error {
+		if artifact.Labels == nil {
+			artifact.Labels = map[string]string{}
+		}
+		artifact.Labels[StorageLabel] = store.Name
+
+		spec := backupv1.BackupArtifactSpec{
+			StorageRef: corev1.LocalObjectReference{Name: store.Name},
+			Key:        object.Key,
+			Size:       object.Size,
+			Checksum:   object.Checksum,
+		}
+		if !object.LastModified.IsZero() {
+			timestamp := metav1.NewTime(object.LastModified.UTC().Truncate(time.Second))
+			spec.Timestamp = &timestamp
+		}
+		// Keep a known owner even once its BackupRun has been pruned
+		switch {
+		case backupName != "":
+			spec.BackupRef = &corev1.LocalObjectReference{Name: backupName}
+			artifact.Labels[BackupLabel] = backupName
+		case artifact.Spec.BackupRef != nil:
+			spec.BackupRef = artifact.Spec.BackupRef
+		}
+		artifact.Spec = spec
+
+		return controllerutil.SetControllerReference(store, artifact, r.Scheme)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to apply backup artifact %s: %w", name, err)
+	}
+	if result == controllerutil.OperationResultCreated {
+		log.FromContext(ctx).Info("Discovered BackupArtifact", "artifact", name, "key", object.Key)
+	}
+	return nil
+}
+
+// tierDirs returns the directories under the Storage path that schedule
+// tiers of Backups upload to
+func (r *BackupArtifactReconciler) tierDirs(ctx context.Context, store *backupv1.Storage) ([]string, error) {
+	backups := &backupv1.BackupList{}
+	if err := r.List(ctx, backups, client.InNamespace(store.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+	var dirs []string
+	for i := range backups.Items {
+		for _, dir := range tierDirs(&backups.Items[i], store.Name) {
+			if !slices.Contains(dirs, dir) {
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+	slices.Sort(dirs)
+	return dirs, nil
+}
+
+// artifactOwnerIndex resolves which Backup created an archive in a Storage
+type artifactOwnerIndex struct {
+	// byFilename maps archive names recorded by BackupRuns to their Backup
+	byFilename map[string]string
+}
+
+// backupFor returns the Backup that created an archive, or "" when no
+// BackupRun recorded it
+func (i artifactOwnerIndex) backupFor(key string) string {
+	return i.byFilename[key]
+}
+
+// artifactOwners indexes the archives uploaded to a Storage by their Backup.
+// Only archives recorded by BackupRuns are attributed; archives of deleted
+// Backups, of runs outside the operator or older than any BackupRun stay
+// unowned.
+func artifactOwners(ctx context.Context, c client.Reader, namespace, storageName string) (artifactOwnerIndex, error) {
+	index := artifactOwnerIndex{byFilename: map[string]string{}}
+
+	runs := &backupv1.BackupRunList{}
+	if err := c.List(ctx, runs, client.InNamespace(namespace)); err != nil {
+		return index, fmt.Errorf("failed to list backup runs: %w", err)
+	}
+	for _, run := range runs.Items {
+		artifact := run.Status.Artifact
+		if artifact == nil {
+			continue
+		}
+		for _, name := range artifact.Storages {
+			if name == storageName {
+				index.byFilename[artifact.Filename] = run.Spec.BackupRef.Name
+			}
+		}
+	}
+
+	return index, nil
+}
+
+// isArtifactKey reports whether an object name is a gobackup archive, at the
+// storage path or in the directory of a schedule tier
+func isArtifactKey(key string) bool {
+	name := path.Base(key)
+	return artifactFilenamePattern.FindString(name) == name
+}
+
+// artifactObjectName derives a stable BackupArtifact name from the Storage
+// name and the object key
+func artifactObjectName(storageName, key string) string {
+	name := storageName + "-" + strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(key), "-"), "-.")
+	if len(name) <= 253 {
+		return name
+	}
+	sum := sha256.Sum256([]byte(key))
+	return fmt.Sprintf("%.236s-%s", storageName, hex.EncodeToString(sum[:8]))
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *BackupArtifactReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("backupartifact").
+		For(&backupv1.Storage{}).
+		Watches(&backupv1.BackupRun{}, handler.EnqueueRequestsFromMapFunc(r.findStoragesForBackupRun)).
+		Complete(r)
+}
+
+// findStoragesForBackupRun lists the Storages a finished BackupRun uploaded
+// to, so new archives show up without waiting for the next sync
+func (r *BackupArtifactReconciler) findStoragesForBackupRun(ctx context.Context, obj client.Object) []ctrl.Request {
+	run, ok := obj.(*backupv1.BackupRun)
+	if !ok || run.Status.Artifact == nil {
+		return nil
+	}
+
+	requests := []ctrl.Request{}
+	for _, name := range run.Status.Artifact.Storages {
+		requests = append(requests, ctrl.Request{
+			NamespacedName: types.NamespacedName{Name: name, Namespace: run.Namespace},
+		})
+	}
+	return requests
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+	"github.com/gobackup/gobackup-operator/pkg/storage"
+)
+
+// newTestScheme returns a scheme with the built-in and gobackup.io types
+func newTestScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add client-go types: %v", err)
+	}
+	if err := backupv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add gobackup.io types: %v", err)
+	}
+	return scheme
+}
+
+// newTestClient returns a fake client holding objs
+func newTestClient(t *testing.T, objs ...client.Object) client.Client {
+	t.Helper()
+	return fake.NewClientBuilder().
+		WithScheme(newTestScheme(t)).
+		WithObjects(objs...).
+		WithStatusSubresource(&backupv1.Backup{}, &backupv1.BackupRun{}, &backupv1.Database{}, &backupv1.Storage{}, &backupv1.Restore{}).
+		Build()
+}
+
+// testRun returns a BackupRun of backup that uploaded filename to storages
+func testRun(name, backup, filename string, storages ...string) *backupv1.BackupRun {
+	run := &backupv1.BackupRun{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       backupv1.BackupRunSpec{BackupRef: corev1.LocalObjectReference{Name: backup}},
+	}
+	if filename != "" {
+		run.Status.Artifact = &backupv1.BackupArtifactInfo{Filename: filename, Storages: storages}
+	}
+	return run
+}
+
+func TestArtifactOwners(t *testing.T) {
+	c := newTestClient(t,
+		testRun("app-1", "app", "2024.06.15.01.00.00.tar.gz", "s3"),
+		testRun("app-2", "app", "daily/2024.06.16.01.00.00.tar.gz", "s3", "local"),
+		testRun("other-1", "other", "2024.06.15.02.00.00.tar.gz", "local"),
+		testRun("app-3", "app", ""),
+		// The only Backup using s3; its unrecorded archives stay unowned
+		&backupv1.Backup{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+			Spec:       backupv1.BackupSpec{StorageRefs: []backupv1.StorageRef{{Name: "s3"}}},
+		},
+	)
+
+	owners, err := artifactOwners(context.Background(), c, "default", "s3")
+	if err != nil {
+		t.Fatalf("artifactOwners failed: %v", err)
+	}
+
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "2024.06.15.01.00.00.tar.gz", want: "app"},
+		{key: "daily/2024.06.16.01.00.00.tar.gz", want: "app"},
+		{key: "2024.06.15.02.00.00.tar.gz", want: ""},
+		{key: "2023.01.01.00.00.00.tar.gz", want: ""},
+	}
+	for _, tt := range tests {
+		if got := owners.backupFor(tt.key); got != tt.want {
+			t.Errorf("backupFor(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestApplyArtifact(t *testing.T) {
+	store := &backupv1.Storage{ObjectMeta: metav1.ObjectMeta{Name: "s3", Namespace: "default", UID: "uid"}}
+	object := storage.Object{Key: "2024.06.15.01.00.00.tar.gz", Size: 42, LastModified: time.Unix(1718413200, 0)}
+	name := artifactObjectName(store.Name, object.Key)
+
+	tests := []struct {
+		name     string
+		existing string
+		backup   string
+		want     string
+	}{
+		{name: "recorded owner", backup: "app", want: "app"},
+		{name: "unknown owner", want: ""},
+		{name: "owner kept after its run was pruned", existing: "app", want: "app"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objs := []client.Object{store}
+			if tt.existing != "" {
+				objs = append(objs, &backupv1.BackupArtifact{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default",
+						Labels: map[string]string{StorageLabel: store.Name, BackupLabel: tt.existing}},
+					Spec: backupv1.BackupArtifactSpec{StorageRef: corev1.LocalObjectReference{Name: store.Name},
+						Key: object.Key, BackupRef: &corev1.LocalObjectReference{Name: tt.existing}},
+				})
+			}
+			c := newTestClient(t, objs...)
+			r := &BackupArtifactReconciler{Client: c, Scheme: c.Scheme()}
+			if err := r.applyArtifact(context.Background(), store, object, name, tt.backup); err != nil {
+				t.Fatalf("applyArtifact failed: %v", err)
+			}
+
+			artifact := &backupv1.BackupArtifact{}
+			if err := c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: name}, artifact); err != nil {
+				t.Fatalf("failed to get artifact: %v", err)
+			}
+			got := ""
+			if artifact.Spec.BackupRef != nil {
+				got = artifact.Spec.BackupRef.Name
+			}
+			if got != tt.want || artifact.Labels[BackupLabel] != tt.want {
+				t.Errorf("backupRef = %q, label = %q, want %q", got, artifact.Labels[BackupLabel], tt.want)
+			}
+			if artifact.Spec.Size != object.Size || artifact.Spec.Key != object.Key {
+				t.Errorf("spec = %+v, want the key and size of %+v", artifact.Spec, object)
+			}
+		})
+	}
+}
+
+func TestBackupArtifactReconcileUnsupportedStorage(t *testing.T) {
+	store := &backupv1.Storage{ObjectMeta: metav1.ObjectMeta{Name: "azure", Namespace: "default"}, Spec: backupv1.StorageSpec{Type: "azure"}}
+	artifact := &backupv1.BackupArtifact{ObjectMeta: metav1.ObjectMeta{Name: "azure-2024.06.15.01.00.00.tar.gz", Namespace: "default",
+		Labels: map[string]string{StorageLabel: store.Name}}}
+	c := newTestClient(t, store, artifact)
+	// The storage is neither resolved nor listed, so no K8s client is needed
+	r := &BackupArtifactReconciler{Client: c, Scheme: c.Scheme()}
+
+	for _, name := range []string{store.Name, "missing"} {
+		result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: name, Namespace: "default"}})
+		if err != nil || result.RequeueAfter != 0 {
+			t.Errorf("Reconcile(%s) = %+v, %v, want no requeue", name, result, err)
+		}
+	}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(artifact), &backupv1.BackupArtifact{}); err != nil {
+		t.Errorf("artifact of an unsupported storage was touched: %v", err)
+	}
+}
+
+func TestBackupArtifactTierDirs(t *testing.T) {
+	tier := func(name string, storages ...string) backupv1.BackupScheduleTier {
+		return backupv1.BackupScheduleTier{Name: name, StorageRefs: storages}
+	}
+	app := testBackup("app", nil, []string{"s3", "local"})
+	app.Spec.Schedules = []backupv1.BackupScheduleTier{tier("weekly", "s3"), tier("daily")}
+	db := testBackup("db", nil, []string{"s3"})
+	db.Spec.Schedules = []backupv1.BackupScheduleTier{tier("daily"), tier("monthly", "local")}
+	c := newTestClient(t, app, db, testBackup("plain", nil, []string{"s3"}))
+	r := &BackupArtifactReconciler{Client: c, Scheme: c.Scheme()}
+
+	tests := []struct {
+		storage string
+		want    []string
+	}{
+		{storage: "s3", want: []string{"daily", "weekly"}},
+		{storage: "local", want: []string{"daily", "monthly"}},
+		{storage: "gcs", want: []string{"daily"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.storage, func(t *testing.T) {
+			store := &backupv1.Storage{ObjectMeta: metav1.ObjectMeta{Name: tt.storage, Namespace: "default"}}
+			got, err := r.tierDirs(context.Background(), store)
+			if err != nil {
+				t.Fatalf("tierDirs failed: %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("tierDirs = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
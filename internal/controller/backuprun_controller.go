@@ -0,0 +1,388 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+	"github.com/gobackup/gobackup-operator/pkg/k8sutil"
+)
+
+// artifactFilenamePattern matches the archive names gobackup generates from the
+// run's start time, e.g. 2024.01.02.03.04.05.tar.gz
+var artifactFilenamePattern = regexp.MustCompile(`\d{4}(?:\.\d{2}){5}\.tar(?:\.[A-Za-z0-9]+)*`)
+
+// BackupRunReconciler reconciles a BackupRun object
+type BackupRunReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	K8s       *k8sutil.K8s
+	Clientset *kubernetes.Clientset
+}
+
+// +kubebuilder:rbac:groups=gobackup.io,resources=backupruns,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gobackup.io,resources=backupruns/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=gobackup.io,resources=backupruns/finalizers,verbs=update
+
+// Reconcile starts the Job for a BackupRun, or adopts the Job a BackupRun was
+// recorded for, and mirrors the Job's progress into the BackupRun status.
+func (r *BackupRunReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	logger.Info("Reconciling BackupRun", "namespace", req.Namespace, "name", req.Name)
+
+	run := &backupv1.BackupRun{}
+	if err := r.Get(ctx, req.NamespacedName, run); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if err := refreshJobDefaults(ctx, r.Client); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if !run.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	// Finished runs are history; nothing left to do
+	if run.Status.Phase == "Succeeded" || run.Status.Phase == "Failed" {
+		return ctrl.Result{}, nil
+	}
+
+	backup := &backupv1.Backup{}
+	if err := r.Get(ctx, types.NamespacedName{Name: run.Spec.BackupRef.Name, Namespace: run.Namespace}, backup); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, r.failRun(ctx, run, fmt.Sprintf("Backup %s not found", run.Spec.BackupRef.Name))
+		}
+		return ctrl.Result{}, err
+	}
+
+	if err := r.ensureBackupOwnership(ctx, run, backup); err != nil {
+		logger.Error(err, "Failed to link BackupRun to its Backup")
+		return ctrl.Result{}, err
+	}
+
+	// A failed attempt waits for spec.runPolicy.retryDelay. Its Job may be
+	// gone by then, so the retry is driven by the run status alone.
+	if run.Status.Phase == "Retrying" {
+		return r.retryRun(ctx, run, backup)
+	}
+
+	jobName := run.Status.JobName
+	if jobName == "" {
+		jobName = run.Name
+	}
+
+	job := &batchv1.Job{}
+	err := r.Get(ctx, types.NamespacedName{Name: jobName, Namespace: run.Namespace}, job)
+	if err != nil && !errors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+
+	if errors.IsNotFound(err) {
+		if run.Status.JobName != "" {
+			return ctrl.Result{}, r.failRun(ctx, run, fmt.Sprintf("Job %s was deleted before the run finished", jobName))
+		}
+		// Recorded runs follow a Job the Backup started; only runs created
+		// by users start their own
+		if _, ok := run.Annotations[RecordedAnnotation]; ok {
+			return ctrl.Result{}, r.failRun(ctx, run, fmt.Sprintf("Job %s was deleted before its run was recorded", jobName))
+		}
+		if run.Spec.Tier != "" && findScheduleTier(backup, run.Spec.Tier) == nil {
+			return ctrl.Result{}, r.failRun(ctx, run, fmt.Sprintf("Backup %s has no schedule tier %s", backup.Name, run.Spec.Tier))
+		}
+		if err := r.startRun(ctx, run, backup); err != nil {
+			logger.Error(err, "Failed to start backup run")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	if run.Status.JobName == "" && !jobBelongsToBackup(job, backup) {
+		return ctrl.Result{}, r.failRun(ctx, run, fmt.Sprintf("Job %s exists and does not belong to Backup %s", job.Name, backup.Name))
+	}
+
+	// Pending runs are rechecked for images that cannot be pulled
+	phase := getJobPhase(job)
+	if run.Status.JobName == job.Name && run.Status.Phase == phase && phase != "Pending" {
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	status := r.buildRunStatus(ctx, job, backup)
+	if status.StartTime == nil {
+		status.StartTime = run.Status.StartTime
+	}
+	status.Attempts = run.Status.Attempts
+	if status.Phase == "Failed" && canRetry(backup, run, job) {
+		failedAt := jobFailedAt(job)
+		status.Phase = "Retrying"
+		status.CompletionTime = &failedAt
+		status.Attempts = runAttempts(run)
+		status.Message = truncateString(fmt.Sprintf("Attempt %d failed (%s), retrying in %s: %s",
+			status.Attempts, status.Reason, backup.Spec.RunPolicy.RetryDelay.Duration, status.Message), MaxMessageSize)
+	}
+	if equality.Semantic.DeepEqual(run.Status, status) {
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+	run.Status = status
+	if err := r.Status().Update(ctx, run); err != nil {
+		if errors.IsConflict(err) {
+			return ctrl.Result{Requeue: true}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("failed to update backup run status: %w", err)
+	}
+	logger.Info("Updated BackupRun status", "job", job.Name, "phase", status.Phase)
+
+	if status.Phase == "Retrying" {
+		return ctrl.Result{RequeueAfter: retryAfter(backup, run)}, nil
+	}
+	if status.Phase == "Running" || status.Phase == "Pending" || status.Phase == "Queued" || status.Phase == "Waiting" {
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// retryRun starts the next attempt of a Retrying run once the retry delay
+// has passed. The attempt's Job skips spec.dependsOn, which the run already
+// waited for.
+func (r *BackupRunReconciler) retryRun(ctx context.Context, run *backupv1.BackupRun, backup *backupv1.Backup) (ctrl.Result, error) {
+	if wait := retryAfter(backup, run); wait > 0 {
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
+	attempt := runAttempts(run) + 1
+	name := retryJobName(run, attempt)
+	if err := r.createRunJob(ctx, run, backup, name, true); err != nil && !errors.IsAlreadyExists(err) {
+		return ctrl.Result{}, err
+	}
+	log.FromContext(ctx).Info("Retrying backup run", "job", name, "attempt", attempt)
+
+	run.Status.JobName = name
+	run.Status.Phase = "Pending"
+	run.Status.Attempts = attempt
+	run.Status.Message = fmt.Sprintf("Attempt %d started", attempt)
+	run.Status.Reason = ""
+	run.Status.ExitCode = nil
+	run.Status.Logs = ""
+	run.Status.CompletionTime = nil
+	if err := r.Status().Update(ctx, run); err != nil {
+		if errors.IsConflict(err) {
+			return ctrl.Result{Requeue: true}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("failed to update backup run status: %w", err)
+	}
+	return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+}
+
+// ensureBackupOwnership makes the Backup own the BackupRun and labels it with
+// the Backup name, so runs are listed per Backup and garbage collected with it.
+func (r *BackupRunReconciler) ensureBackupOwnership(ctx context.Context, run *backupv1.BackupRun, backup *backupv1.Backup) error {
+	owned := false
+	for _, ref := range run.OwnerReferences {
+		if ref.UID == backup.UID {
+			owned = true
+			break
+		}
+	}
+	if owned && run.Labels[BackupLabel] == backup.Name {
+		return nil
+	}
+
+	if err := controllerutil.SetOwnerReference(backup, run, r.Scheme); err != nil {
+		return fmt.Errorf("failed to set owner reference for BackupRun: %w", err)
+	}
+	if run.Labels == nil {
+		run.Labels = map[string]string{}
+	}
+	run.Labels[BackupLabel] = backup.Name
+	return r.Update(ctx, run)
+}
+
+// startRun renders the Backup configuration and creates the Job for an
+// on-demand BackupRun. The Job is named after the BackupRun and owned by it.
+func (r *BackupRunReconciler) startRun(ctx context.Context, run *backupv1.BackupRun, backup *backupv1.Backup) error {
+	if _, err := r.K8s.CreateSecret(ctx, backup); err != nil {
+		return fmt.Errorf("failed to create secret for backup run: %w", err)
+	}
+
+	if err := r.createRunJob(ctx, run, backup, run.Name, false); err != nil {
+		return err
+	}
+
+	now := metav1.Now()
+	run.Status.JobName = run.Name
+	run.Status.Phase = "Pending"
+	run.Status.StartTime = &now
+	if err := r.Status().Update(ctx, run); err != nil {
+		return fmt.Errorf("failed to update backup run status: %w", err)
+	}
+	return nil
+}
+
+// createRunJob creates a Job of a BackupRun, owned by it and labelled with
+// its name. Retries skip waiting for spec.dependsOn.
+func (r *BackupRunReconciler) createRunJob(ctx context.Context, run *backupv1.BackupRun, backup *backupv1.Backup, name string, retry bool) error {
+	jobTemplate, err := buildJobTemplate(backup, run.Spec.Tier)
+	if err != nil {
+		return err
+	}
+	labels := map[string]string{BackupRunLabel: run.Name}
+	for k, v := range jobTemplate.Labels {
+		labels[k] = v
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   run.Namespace,
+			Labels:      labels,
+			Annotations: jobTemplate.Annotations,
+		},
+		Spec: jobTemplate.Spec,
+	}
+	if retry {
+		releaseDependencyWait(&job.ObjectMeta, &job.Spec)
+	}
+	if err := controllerutil.SetControllerReference(run, job, r.Scheme); err != nil {
+		return fmt.Errorf("failed to set controller reference for BackupRun Job: %w", err)
+	}
+	if err := r.Create(ctx, job); err != nil {
+		return fmt.Errorf("failed to create BackupRun Job: %w", err)
+	}
+	return nil
+}
+
+// buildRunStatus creates the BackupRun status from its Job. Logs are kept for
+// every finished run, and successful runs record the uploaded artifact.
+func (r *BackupRunReconciler) buildRunStatus(ctx context.Context, job *batchv1.Job, backup *backupv1.Backup) backupv1.BackupRunStatus {
+	logger := log.FromContext(ctx)
+
+	status := jobRunStatus(job)
+	classifyRun(ctx, r.Client, job, &status)
+	if status.Phase != "Succeeded" && status.Phase != "Failed" {
+		return status
+	}
+
+	logs, err := collectPodLogs(ctx, r.Client, r.Clientset, job, "gobackup")
+	if err != nil {
+		logger.V(1).Info("Failed to collect pod logs", "error", err)
+		status.Logs = truncateString(fmt.Sprintf("Failed to collect logs: %v", err), MaxLogSize)
+		return status
+	}
+	status.Logs = logs
+
+	if status.Phase == "Succeeded" {
+		status.Artifact = parseArtifactInfo(logs, backup, job.Labels[TierLabel])
+	}
+	return status
+}
+
+// failRun marks a BackupRun as Failed with the given message
+func (r *BackupRunReconciler) failRun(ctx context.Context, run *backupv1.BackupRun, message string) error {
+	now := metav1.Now()
+	run.Status.Phase = "Failed"
+	run.Status.Message = truncateString(message, MaxMessageSize)
+	run.Status.CompletionTime = &now
+	if err := r.Status().Update(ctx, run); err != nil {
+		return fmt.Errorf("failed to update backup run status: %w", err)
+	}
+	return nil
+}
+
+// parseArtifactInfo extracts the archive name from gobackup's output. The last
+// match wins because gobackup logs the final (compressed, encrypted) name last.
+// Archives of a schedule tier are uploaded to the tier's directory of its
+// storages, and named <tier>/<archive>.
+func parseArtifactInfo(logs string, backup *backupv1.Backup, tier string) *backupv1.BackupArtifactInfo {
+	matches := artifactFilenamePattern.FindAllString(logs, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	artifact := &backupv1.BackupArtifactInfo{
+		Filename: strings.TrimSuffix(matches[len(matches)-1], "."),
+	}
+	if tier != "" {
+		artifact.Filename = tier + "/" + artifact.Filename
+	}
+	for _, ref := range backup.Spec.StorageRefs {
+		if tier == "" || slices.Contains(tierDirs(backup, ref.Name), tier) {
+			artifact.Storages = append(artifact.Storages, ref.Name)
+		}
+	}
+	return artifact
+}
+
+// jobBelongsToBackup reports whether a Job was created for the given Backup
+func jobBelongsToBackup(job *batchv1.Job, backup *backupv1.Backup) bool {
+	if name, ok := job.Labels[BackupLabel]; ok {
+		return name == backup.Name
+	}
+	return isLegacyBackupJob(job, backup)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *BackupRunReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&backupv1.BackupRun{}).
+		Watches(&batchv1.Job{}, handler.EnqueueRequestsFromMapFunc(r.findBackupRunForJob)).
+		Complete(r)
+}
+
+// findBackupRunForJob maps a Job to its BackupRun: the run that started it, or
+// for CronJob, one-shot and manual Jobs the run recorded under the Job's name
+func (r *BackupRunReconciler) findBackupRunForJob(ctx context.Context, obj client.Object) []ctrl.Request {
+	job, ok := obj.(*batchv1.Job)
+	if !ok {
+		return nil
+	}
+
+	name, ok := job.Labels[BackupRunLabel]
+	if !ok {
+		if _, ok := job.Labels[BackupLabel]; !ok {
+			return nil
+		}
+		name = job.Name
+	}
+
+	return []ctrl.Request{
+		{
+			NamespacedName: types.NamespacedName{
+				Name:      name,
+				Namespace: job.Namespace,
+			},
+		},
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+)
+
+func TestBackupRunReconcile(t *testing.T) {
+	running := testJob("app-run", map[string]string{BackupLabel: "app"}, nil, "", "")
+	running.Status.Active = 1
+
+	tests := []struct {
+		name        string
+		run         func(*backupv1.BackupRun)
+		jobs        []client.Object
+		wantPhase   string
+		wantMessage string
+		wantRequeue time.Duration
+	}{
+		{
+			name:      "finished run",
+			run:       func(run *backupv1.BackupRun) { run.Status.Phase = "Succeeded" },
+			wantPhase: "Succeeded",
+		},
+		{
+			name:        "missing backup",
+			run:         func(run *backupv1.BackupRun) { run.Spec.BackupRef.Name = "gone" },
+			wantPhase:   "Failed",
+			wantMessage: "Backup gone not found",
+		},
+		{
+			name:        "job deleted while running",
+			run:         func(run *backupv1.BackupRun) { run.Status.JobName, run.Status.Phase = "app-run", "Running" },
+			wantPhase:   "Failed",
+			wantMessage: "was deleted before the run finished",
+		},
+		{
+			name:        "job of a recorded run deleted",
+			run:         func(run *backupv1.BackupRun) { run.Annotations = map[string]string{RecordedAnnotation: "true"} },
+			wantPhase:   "Failed",
+			wantMessage: "was deleted before its run was recorded",
+		},
+		{
+			name:        "unknown tier",
+			run:         func(run *backupv1.BackupRun) { run.Spec.Tier = "weekly" },
+			wantPhase:   "Failed",
+			wantMessage: "has no schedule tier weekly",
+		},
+		{
+			name:        "job of another backup",
+			jobs:        []client.Object{testJob("app-run", map[string]string{BackupLabel: "other"}, nil, "", "")},
+			wantPhase:   "Failed",
+			wantMessage: "does not belong to Backup app",
+		},
+		{
+			name:        "running job",
+			jobs:        []client.Object{running},
+			wantPhase:   "Running",
+			wantRequeue: 30 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := &backupv1.BackupRun{ObjectMeta: metav1.ObjectMeta{Name: "app-run", Namespace: "default"}}
+			run.Spec.BackupRef.Name = "app"
+			if tt.run != nil {
+				tt.run(run)
+			}
+			backup := &backupv1.Backup{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: "Backup-app"}}
+			c := newTestClient(t, append(tt.jobs, run, backup)...)
+			r := &BackupRunReconciler{Client: c, Scheme: c.Scheme()}
+
+			key := types.NamespacedName{Name: "app-run", Namespace: "default"}
+			result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+			if err != nil {
+				t.Fatalf("Reconcile failed: %v", err)
+			}
+			if result.RequeueAfter != tt.wantRequeue {
+				t.Errorf("RequeueAfter = %v, want %v", result.RequeueAfter, tt.wantRequeue)
+			}
+
+			got := &backupv1.BackupRun{}
+			if err := c.Get(context.Background(), key, got); err != nil {
+				t.Fatalf("failed to get backup run: %v", err)
+			}
+			if got.Status.Phase != tt.wantPhase {
+				t.Errorf("phase = %q, want %q", got.Status.Phase, tt.wantPhase)
+			}
+			if !strings.Contains(got.Status.Message, tt.wantMessage) {
+				t.Errorf("message = %q, want it to contain %q", got.Status.Message, tt.wantMessage)
+			}
+			if tt.wantPhase == "Failed" && got.Status.CompletionTime == nil {
+				t.Error("failed run has no completionTime")
+			}
+			linked := got.Labels[BackupLabel] == "app" && slices.ContainsFunc(got.OwnerReferences, func(ref metav1.OwnerReference) bool {
+				return ref.UID == backup.UID
+			})
+			if wantLinked := tt.wantPhase != "Succeeded" && got.Spec.BackupRef.Name == "app"; linked != wantLinked {
+				t.Errorf("linked to Backup = %v, want %v", linked, wantLinked)
+			}
+		})
+	}
+}
+
+func TestFindBackupRunForJob(t *testing.T) {
+	tests := []struct {
+		name string
+		job  *batchv1.Job
+		want []string
+	}{
+		{name: "job of a backup run", job: testJob("app-run-retry-2", map[string]string{BackupLabel: "app", BackupRunLabel: "app-run"}, nil, "", ""), want: []string{"app-run"}},
+		{name: "job started by a backup", job: testJob("app-28512345", map[string]string{BackupLabel: "app"}, nil, "", ""), want: []string{"app-28512345"}},
+		{name: "unrelated job", job: testJob("migrate", nil, nil, "", ""), want: []string{}},
+	}
+	r := &BackupRunReconciler{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := requestNames(r.findBackupRunForJob(context.Background(), tt.job)); !slices.Equal(got, tt.want) {
+				t.Errorf("findBackupRunForJob = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+	"github.com/gobackup/gobackup-operator/pkg/k8sutil"
+)
+
+// DatabaseReconciler reports in the status of Databases whether they can be
+// backed up, and which Backups reference them
+type DatabaseReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	K8s    *k8sutil.K8s
+}
+
+// +kubebuilder:rbac:groups=gobackup.io,resources=databases,verbs=get;list;watch
+// +kubebuilder:rbac:groups=gobackup.io,resources=databases/status,verbs=get;update;patch
+
+// Reconcile resolves the Secrets referenced by a Database, checks that its
+// configuration renders, and records the Ready and SecretsResolved
+// conditions, the target of its serviceRef and the Backups referencing it. Databases are checked again
+// every ReadinessCheckInterval.
+func (r *DatabaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	database := &backupv1.Database{}
+	if err := r.Get(ctx, req.NamespacedName, database); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !database.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	secretsErr := r.K8s.ResolveSecretReferences(ctx, database.Namespace, &database.Spec.Config)
+	var target *backupv1.ServiceTarget
+	var configErr error
+	if secretsErr == nil {
+		target, configErr = r.checkDatabase(ctx, database)
+	}
+	backups, err := referencingBackups(ctx, r.Client, database.Namespace, func(backup *backupv1.Backup) bool {
+		return slices.ContainsFunc(backup.Spec.DatabaseRefs, func(ref backupv1.DatabaseRef) bool { return ref.Name == database.Name })
+	})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	original := database.DeepCopy()
+	database.Status.ObservedGeneration = database.Generation
+	database.Status.Backups = backups
+	// Keep the last target while the configuration does not render
+	if secretsErr == nil && configErr == nil {
+		database.Status.ServiceTarget = target
+	}
+	setReadiness(&database.Status.Conditions, database.Generation, secretsErr, configErr)
+	if !equality.Semantic.DeepEqual(original.Status, database.Status) {
+		if err := r.Status().Patch(ctx, database, client.MergeFrom(original)); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to update database status: %w", err)
+		}
+	}
+	return ctrl.Result{RequeueAfter: ReadinessCheckInterval}, nil
+}
+
+// checkDatabase returns where the serviceRef of a Database with resolved
+// Secrets points, or why it cannot be backed up
+func (r *DatabaseReconciler) checkDatabase(ctx context.Context, database *backupv1.Database) (*backupv1.ServiceTarget, error) {
+	if exec := database.Spec.Exec; exec != nil {
+		if _, err := metav1.LabelSelectorAsSelector(&exec.Selector); err != nil {
+			return nil, fmt.Errorf("invalid exec selector: %w", err)
+		}
+		return nil, nil
+	}
+	// Rendering resolves serviceRef and fromObject as backups would
+	return r.K8s.ResolveServiceTarget(ctx, database.Namespace, database.Name)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *DatabaseReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&backupv1.Database{}).
+		// Status updates of Backups do not change their references
+		Watches(&backupv1.Backup{}, handler.EnqueueRequestsFromMapFunc(r.findDatabasesForBackup),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.findDatabasesForSecret)).
+		Complete(r)
+}
+
+// findDatabasesForBackup maps a Backup to the Databases it references or
+// used to reference
+func (r *DatabaseReconciler) findDatabasesForBackup(ctx context.Context, obj client.Object) []ctrl.Request {
+	backup, ok := obj.(*backupv1.Backup)
+	if !ok {
+		return nil
+	}
+	databases := &backupv1.DatabaseList{}
+	if err := r.List(ctx, databases, client.InNamespace(backup.Namespace)); err != nil {
+		return nil
+	}
+
+	requests := []ctrl.Request{}
+	for _, database := range databases.Items {
+		referenced := slices.ContainsFunc(backup.Spec.DatabaseRefs, func(ref backupv1.DatabaseRef) bool { return ref.Name == database.Name })
+		if referenced || slices.Contains(database.Status.Backups, backup.Name) {
+			requests = append(requests, ctrl.Request{
+				NamespacedName: types.NamespacedName{Name: database.Name, Namespace: database.Namespace},
+			})
+		}
+	}
+	return requests
+}
+
+// findDatabasesForSecret maps a Secret to the Databases of its namespace
+// referencing it
+func (r *DatabaseReconciler) findDatabasesForSecret(ctx context.Context, obj client.Object) []ctrl.Request {
+	databases := &backupv1.DatabaseList{}
+	if err := r.List(ctx, databases, client.InNamespace(obj.GetNamespace())); err != nil {
+		return nil
+	}
+
+	requests := []ctrl.Request{}
+	for _, database := range databases.Items {
+		names := configSecretNames(&database.Spec.Config)
+		if ref := database.Spec.Config.FromObject; ref != nil && ref.APIVersion == "v1" && ref.Kind == "Secret" {
+			names = append(names, ref.Name)
+		}
+		if slices.Contains(names, obj.GetName()) {
+			requests = append(requests, ctrl.Request{
+				NamespacedName: types.NamespacedName{Name: database.Name, Namespace: database.Namespace},
+			})
+		}
+	}
+	return requests
+}
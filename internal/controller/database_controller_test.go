@@ -0,0 +1,202 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+	"github.com/gobackup/gobackup-operator/pkg/k8sutil"
+)
+
+// testBackup returns a Backup referencing the given Databases and Storages
+func testBackup(name string, databases, storages []string) *backupv1.Backup {
+	backup := &backupv1.Backup{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+	for _, database := range databases {
+		backup.Spec.DatabaseRefs = append(backup.Spec.DatabaseRefs, backupv1.DatabaseRef{Name: database})
+	}
+	for _, storage := range storages {
+		backup.Spec.StorageRefs = append(backup.Spec.StorageRefs, backupv1.StorageRef{Name: storage})
+	}
+	return backup
+}
+
+// requestNames returns the sorted names of requests
+func requestNames(requests []ctrl.Request) []string {
+	names := []string{}
+	for _, request := range requests {
+		names = append(names, request.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestDatabaseReconcile(t *testing.T) {
+	exec := &backupv1.DatabaseExec{Selector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}}
+	stale := &backupv1.ServiceTarget{Host: "db.default.svc", Port: 5432}
+
+	tests := []struct {
+		name       string
+		spec       backupv1.DatabaseSpec
+		ready      metav1.ConditionStatus
+		reason     string
+		secrets    metav1.ConditionStatus
+		wantTarget *backupv1.ServiceTarget
+	}{
+		{
+			name:    "exec database",
+			spec:    backupv1.DatabaseSpec{Type: "postgresql", Exec: exec},
+			ready:   metav1.ConditionTrue,
+			reason:  "Ready",
+			secrets: metav1.ConditionTrue,
+		},
+		{
+			name: "invalid exec selector",
+			spec: backupv1.DatabaseSpec{Type: "postgresql", Exec: &backupv1.DatabaseExec{Selector: metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Near"}},
+			}}},
+			ready:      metav1.ConditionFalse,
+			reason:     "InvalidConfig",
+			secrets:    metav1.ConditionTrue,
+			wantTarget: stale,
+		},
+		{
+			name: "secret reference without key",
+			spec: backupv1.DatabaseSpec{Type: "postgresql", Exec: exec, Config: backupv1.DatabaseConfig{
+				PasswordRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}},
+			}},
+			ready:      metav1.ConditionFalse,
+			reason:     "SecretsUnresolved",
+			secrets:    metav1.ConditionFalse,
+			wantTarget: stale,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database := &backupv1.Database{
+				ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default", Generation: 3},
+				Spec:       tt.spec,
+				Status:     backupv1.DatabaseStatus{ServiceTarget: stale},
+			}
+			c := newTestClient(t, database,
+				testBackup("b", []string{"db"}, nil),
+				testBackup("a", []string{"other", "db"}, nil),
+				testBackup("c", []string{"other"}, nil),
+			)
+			r := &DatabaseReconciler{Client: c, Scheme: c.Scheme(), K8s: &k8sutil.K8s{}}
+
+			key := types.NamespacedName{Name: "db", Namespace: "default"}
+			result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+			if err != nil {
+				t.Fatalf("Reconcile failed: %v", err)
+			}
+			if result.RequeueAfter != ReadinessCheckInterval {
+				t.Errorf("RequeueAfter = %v, want %v", result.RequeueAfter, ReadinessCheckInterval)
+			}
+
+			got := &backupv1.Database{}
+			if err := c.Get(context.Background(), key, got); err != nil {
+				t.Fatalf("failed to get database: %v", err)
+			}
+			if got.Status.ObservedGeneration != got.Generation {
+				t.Errorf("observedGeneration = %d, want %d", got.Status.ObservedGeneration, got.Generation)
+			}
+			if want := []string{"a", "b"}; !slices.Equal(got.Status.Backups, want) {
+				t.Errorf("backups = %v, want %v", got.Status.Backups, want)
+			}
+			ready := meta.FindStatusCondition(got.Status.Conditions, ConditionReady)
+			if ready == nil || ready.Status != tt.ready || ready.Reason != tt.reason {
+				t.Errorf("Ready = %+v, want %s/%s", ready, tt.ready, tt.reason)
+			}
+			if !meta.IsStatusConditionPresentAndEqual(got.Status.Conditions, ConditionSecretsResolved, tt.secrets) {
+				t.Errorf("SecretsResolved is not %s", tt.secrets)
+			}
+			if (got.Status.ServiceTarget == nil) != (tt.wantTarget == nil) ||
+				got.Status.ServiceTarget != nil && *got.Status.ServiceTarget != *tt.wantTarget {
+				t.Errorf("serviceTarget = %+v, want %+v", got.Status.ServiceTarget, tt.wantTarget)
+			}
+		})
+	}
+}
+
+func TestFindDatabasesForBackup(t *testing.T) {
+	unreferenced := &backupv1.Database{ObjectMeta: metav1.ObjectMeta{Name: "old", Namespace: "default"}}
+	unreferenced.Status.Backups = []string{"app"}
+	c := newTestClient(t,
+		&backupv1.Database{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"}},
+		&backupv1.Database{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "other"}},
+		&backupv1.Database{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "default"}},
+		unreferenced,
+	)
+	r := &DatabaseReconciler{Client: c, Scheme: c.Scheme()}
+
+	tests := []struct {
+		name string
+		obj  client.Object
+		want []string
+	}{
+		{name: "referenced and previously referenced", obj: testBackup("app", []string{"db"}, nil), want: []string{"db", "old"}},
+		{name: "no references", obj: testBackup("other", nil, nil), want: []string{}},
+		{name: "not a backup", obj: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"}}, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := requestNames(r.findDatabasesForBackup(context.Background(), tt.obj)); !slices.Equal(got, tt.want) {
+				t.Errorf("findDatabasesForBackup = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindDatabasesForSecret(t *testing.T) {
+	withRef := &backupv1.Database{ObjectMeta: metav1.ObjectMeta{Name: "password", Namespace: "default"}}
+	withRef.Spec.Config.PasswordRef = &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "creds"}, Key: "password"}
+	fromSecret := &backupv1.Database{ObjectMeta: metav1.ObjectMeta{Name: "from-secret", Namespace: "default"}}
+	fromSecret.Spec.Config.FromObject = &backupv1.DatabaseObjectRef{APIVersion: "v1", Kind: "Secret", Name: "creds"}
+	fromCluster := &backupv1.Database{ObjectMeta: metav1.ObjectMeta{Name: "from-cluster", Namespace: "default"}}
+	fromCluster.Spec.Config.FromObject = &backupv1.DatabaseObjectRef{APIVersion: "postgresql.cnpg.io/v1", Kind: "Cluster", Name: "creds"}
+	c := newTestClient(t, withRef, fromSecret, fromCluster,
+		&backupv1.Database{ObjectMeta: metav1.ObjectMeta{Name: "plain", Namespace: "default"}},
+	)
+	r := &DatabaseReconciler{Client: c, Scheme: c.Scheme()}
+
+	tests := []struct {
+		name   string
+		secret string
+		want   []string
+	}{
+		{name: "referenced secret", secret: "creds", want: []string{"from-secret", "password"}},
+		{name: "unreferenced secret", secret: "other", want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: tt.secret, Namespace: "default"}}
+			if got := requestNames(r.findDatabasesForSecret(context.Background(), secret)); !slices.Equal(got, tt.want) {
+				t.Errorf("findDatabasesForSecret = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+)
+
+const (
+	// WaitingAnnotation marks a backup Job created suspended, waiting for the
+	// Backups in spec.dependsOn. Its value is what the Job is waiting for.
+	WaitingAnnotation = "gobackup.io/waiting"
+
+	// DefaultDependencyWindow is how long before a run a dependency may have
+	// finished, when its within is not set
+	DefaultDependencyWindow = time.Hour
+
+	// DefaultDependencyTimeout is how long a run waits for its dependencies,
+	// when spec.dependencyTimeout is not set
+	DefaultDependencyTimeout = time.Hour
+)
+
+// reconcileDependencies releases the waiting Jobs of a Backup whose
+// dependencies finished, and fails those that waited longer than
+// spec.dependencyTimeout. Released Jobs go on to the backup queue when it is
+// enabled, and start right away otherwise.
+func (r *BackupReconciler) reconcileDependencies(ctx context.Context, backup *backupv1.Backup) error {
+	logger := log.FromContext(ctx)
+
+	jobs, err := r.listBackupJobs(ctx, backup)
+	if err != nil {
+		return err
+	}
+
+	timeout := DefaultDependencyTimeout
+	if backup.Spec.DependencyTimeout != nil {
+		timeout = backup.Spec.DependencyTimeout.Duration
+	}
+
+	for i := range jobs {
+		job := &jobs[i]
+		if !isWaiting(job) || jobFinished(job) {
+			continue
+		}
+
+		unmet, err := r.unmetDependency(ctx, backup, job.CreationTimestamp.Time)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case unmet == "":
+			if err := r.releaseWaitingJob(ctx, job); err != nil {
+				return err
+			}
+			logger.Info("Dependencies finished, releasing backup job", "job", job.Name)
+		case time.Since(job.CreationTimestamp.Time) > timeout:
+			message := fmt.Sprintf("Dependencies not met within %s: %s", timeout, unmet)
+			if err := r.failWaitingRun(ctx, job, message); err != nil {
+				return err
+			}
+			logger.Info("Gave up waiting for dependencies", "job", job.Name, "reason", unmet)
+		case job.Annotations[WaitingAnnotation] != unmet:
+			patch := client.MergeFrom(job.DeepCopy())
+			job.Annotations[WaitingAnnotation] = unmet
+			if err := r.Patch(ctx, job, patch); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("failed to update waiting job %s: %w", job.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// unmetDependency returns the first dependency of the Backup that has not
+// finished with the required outcome since shortly before the given time,
+// described for the run status, or "" when all of them did
+func (r *BackupReconciler) unmetDependency(ctx context.Context, backup *backupv1.Backup, since time.Time) (string, error) {
+	for _, dependency := range backup.Spec.DependsOn {
+		window := DefaultDependencyWindow
+		if dependency.Within != nil {
+			window = dependency.Within.Duration
+		}
+		outcome := dependency.Outcome
+		if outcome == "" {
+			outcome = backupv1.DependencyOutcomeSucceeded
+		}
+
+		other := &backupv1.Backup{}
+		if err := r.Get(ctx, types.NamespacedName{Name: dependency.Name, Namespace: backup.Namespace}, other); err != nil {
+			if errors.IsNotFound(err) {
+				return fmt.Sprintf("Backup %s not found", dependency.Name), nil
+			}
+			return "", fmt.Errorf("failed to get backup %s: %w", dependency.Name, err)
+		}
+
+		finished := dependencyFinishedAt(other, outcome)
+		if finished == nil || finished.Time.Before(since.Add(-window)) {
+			return fmt.Sprintf("waiting for Backup %s to be %s", dependency.Name, strings.ToLower(string(outcome))), nil
+		}
+	}
+	return "", nil
+}
+
+// dependencyFinishedAt returns when the Backup last finished with the outcome
+func dependencyFinishedAt(backup *backupv1.Backup, outcome backupv1.DependencyOutcome) *metav1.Time {
+	latest := latestSuccessTime(backup)
+	if outcome != backupv1.DependencyOutcomeCompleted {
+		return latest
+	}
+
+	runs := []*backupv1.BackupRunStatus{backup.Status.LastRun}
+	for i := range backup.Status.Tiers {
+		runs = append(runs, backup.Status.Tiers[i].LastRun)
+	}
+	for _, run := range runs {
+		if run == nil || run.Phase != "Failed" || run.CompletionTime == nil {
+			continue
+		}
+		if latest == nil || latest.Before(run.CompletionTime) {
+			latest = run.CompletionTime
+		}
+	}
+	return latest
+}
+
+// releaseWaitingJob hands a Job whose dependencies finished to the backup
+// queue, or resumes it when the queue is disabled
+func (r *BackupReconciler) releaseWaitingJob(ctx context.Context, job *batchv1.Job) error {
+	patch := client.MergeFrom(job.DeepCopy())
+	releaseDependencyWait(&job.ObjectMeta, &job.Spec)
+	if err := r.Patch(ctx, job, patch); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to release waiting job %s: %w", job.Name, err)
+	}
+	return nil
+}
+
+// releaseDependencyWait moves a Job, or Job template, waiting for
+// dependencies on to the backup queue, or lets it start when the queue is
+// disabled
+func releaseDependencyWait(meta *metav1.ObjectMeta, spec *batchv1.JobSpec) {
+	if _, ok := meta.Annotations[WaitingAnnotation]; !ok {
+		return
+	}
+	delete(meta.Annotations, WaitingAnnotation)
+	if queueEnabled() {
+		meta.Annotations[QueuedAnnotation] = "waiting for admission"
+	} else {
+		suspend := false
+		spec.Suspend = &suspend
+	}
+}
+
+// failWaitingRun marks the BackupRun of a Job that waited too long as Failed
+// and deletes the Job, which never started
+func (r *BackupReconciler) failWaitingRun(ctx context.Context, job *batchv1.Job, message string) error {
+	run := &backupv1.BackupRun{}
+	err := r.Get(ctx, types.NamespacedName{Name: job.Name, Namespace: job.Namespace}, run)
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to get BackupRun %s: %w", job.Name, err)
+	}
+	if err == nil {
+		now := metav1.Now()
+		run.Status.JobName = job.Name
+		run.Status.Phase = "Failed"
+		run.Status.Message = truncateString(message, MaxMessageSize)
+		run.Status.CompletionTime = &now
+		if err := r.Status().Update(ctx, run); err != nil {
+			return fmt.Errorf("failed to update backup run status: %w", err)
+		}
+	}
+
+	if err := r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete job %s: %w", job.Name, err)
+	}
+	return nil
+}
+
+// findDependentBackups maps a Backup to the Backups that depend on it, so
+// their waiting runs are released as soon as it finishes
+func (r *BackupReconciler) findDependentBackups(ctx context.Context, obj client.Object) []ctrl.Request {
+	backups := &backupv1.BackupList{}
+	if err := r.List(ctx, backups, client.InNamespace(obj.GetNamespace())); err != nil {
+		return nil
+	}
+
+	var requests []ctrl.Request
+	for _, backup := range backups.Items {
+		for _, dependency := range backup.Spec.DependsOn {
+			if dependency.Name == obj.GetName() {
+				requests = append(requests, ctrl.Request{
+					NamespacedName: types.NamespacedName{Name: backup.Name, Namespace: backup.Namespace},
+				})
+				break
+			}
+		}
+	}
+	return requests
+}
+
+// isWaiting reports whether a Job is waiting for the Backup's dependencies
+func isWaiting(job *batchv1.Job) bool {
+	_, ok := job.Annotations[WaitingAnnotation]
+	return ok && job.Spec.Suspend != nil && *job.Spec.Suspend
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+	"github.com/gobackup/gobackup-operator/pkg/k8sutil"
+)
+
+const (
+	// TemplateHashAnnotation is set on the CronJobs of a Backup to the hash of
+	// the spec the operator rendered for them. A mismatch with a freshly
+	// rendered spec, e.g. after an operator upgrade changed the Job template,
+	// makes the operator update the CronJob.
+	TemplateHashAnnotation = "gobackup.io/template-hash"
+
+	// ReasonDriftCorrected is the reason of the Event emitted when a Secret or
+	// CronJob of a Backup was changed by hand and re-rendered
+	ReasonDriftCorrected = "DriftCorrected"
+)
+
+// cronJobTemplateHash returns the hash of the rendered labels and spec of a
+// CronJob
+func cronJobTemplateHash(cronJob *batchv1.CronJob) (string, error) {
+	data, err := json.Marshal(struct {
+		Labels map[string]string   `json:"labels"`
+		Spec   batchv1.CronJobSpec `json:"spec"`
+	}{cronJob.Labels, cronJob.Spec})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash CronJob %s: %w", cronJob.Name, err)
+	}
+	return k8sutil.ConfigHash(data), nil
+}
+
+// repairSecret re-renders the gobackup.yml Secret of the Backup when it was
+// deleted, or its content no longer matches the hash it was rendered with
+func (r *BackupReconciler) repairSecret(ctx context.Context, backup *backupv1.Backup) error {
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Name: backup.Name, Namespace: backup.Namespace}, secret)
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to get secret %s: %w", backup.Name, err)
+	}
+
+	var drift string
+	switch {
+	case errors.IsNotFound(err):
+		drift = "was deleted"
+	case secret.Annotations[k8sutil.ConfigHashAnnotation] == "":
+		drift = "has no config hash"
+	case k8sutil.ConfigHash(secret.Data["gobackup.yml"]) != secret.Annotations[k8sutil.ConfigHashAnnotation]:
+		drift = "was modified"
+	default:
+		return nil
+	}
+
+	if _, err := r.K8s.CreateSecret(ctx, backup); err != nil {
+		return fmt.Errorf("failed to re-render secret %s: %w", backup.Name, err)
+	}
+	log.FromContext(ctx).Info("Re-rendered drifted secret", "name", backup.Name, "drift", drift)
+	r.Recorder.Eventf(backup, nil, corev1.EventTypeNormal, ReasonDriftCorrected, "RenderSecret",
+		"Secret %s %s, re-rendered it", backup.Name, drift)
+	return nil
+}
+
+// repairCronJob updates a CronJob of the Backup when its template hash is out
+// of date, or fields the operator sets were changed by hand. Fields defaulted
+// by the API server are not compared. An outdated hash means the operator's
+// inputs changed, such as the Backup status, the OperatorConfig or the
+// operator itself, so the CronJob is updated without a DriftCorrected Event;
+// only changes to what the operator last wrote are reported as drift.
+func (r *BackupReconciler) repairCronJob(ctx context.Context, backup *backupv1.Backup, existing *batchv1.CronJob, tier *backupv1.BackupScheduleTier) error {
+	logger := log.FromContext(ctx)
+	desired, err := r.buildCronJob(backup, tier)
+	if err != nil {
+		return err
+	}
+
+	hash := existing.Annotations[TemplateHashAnnotation]
+	if hash != "" && hash != desired.Annotations[TemplateHashAnnotation] {
+		if err := r.updateCronJob(ctx, backup, existing, tier); err != nil {
+			return err
+		}
+		logger.Info("Updated CronJob rendered from an outdated template", "name", existing.Name)
+		return nil
+	}
+
+	var drift string
+	switch {
+	case hash == "":
+		drift = "lost its template hash"
+	case !equality.Semantic.DeepDerivative(desired.Spec, existing.Spec):
+		drift = "was modified"
+	default:
+		for k, v := range desired.Labels {
+			if existing.Labels[k] != v {
+				drift = "was relabelled"
+			}
+		}
+	}
+	if drift == "" {
+		return nil
+	}
+
+	if err := r.updateCronJob(ctx, backup, existing, tier); err != nil {
+		return err
+	}
+	logger.Info("Updated drifted CronJob", "name", existing.Name, "drift", drift)
+	r.Recorder.Eventf(backup, existing, corev1.EventTypeNormal, ReasonDriftCorrected, "RenderCronJob",
+		"CronJob %s %s, updated it", existing.Name, drift)
+	return nil
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+)
+
+func TestRepairCronJob(t *testing.T) {
+	tests := []struct {
+		name string
+		// edit changes the CronJob as last written by the operator
+		edit    func(cronJob *batchv1.CronJob)
+		updated bool
+		event   bool
+	}{
+		{name: "unchanged", edit: func(*batchv1.CronJob) {}},
+		{
+			name: "outdated template",
+			edit: func(cronJob *batchv1.CronJob) {
+				cronJob.Annotations[TemplateHashAnnotation] = "outdated"
+				cronJob.Spec.Schedule = "0 3 * * *"
+			},
+			updated: true,
+		},
+		{
+			name: "modified by hand",
+			edit: func(cronJob *batchv1.CronJob) {
+				cronJob.Spec.Schedule = "0 3 * * *"
+			},
+			updated: true,
+			event:   true,
+		},
+		{
+			name: "relabelled by hand",
+			edit: func(cronJob *batchv1.CronJob) {
+				cronJob.Labels[BackupLabel] = "other"
+			},
+			updated: true,
+			event:   true,
+		},
+		{
+			name: "template hash removed",
+			edit: func(cronJob *batchv1.CronJob) {
+				delete(cronJob.Annotations, TemplateHashAnnotation)
+			},
+			updated: true,
+			event:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backup := &backupv1.Backup{
+				ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: "Backup-app"},
+				Spec:       backupv1.BackupSpec{Schedule: &backupv1.BackupSchedule{Cron: "0 2 * * *"}},
+			}
+			scheme := newTestScheme(t)
+			recorder := events.NewFakeRecorder(10)
+			r := &BackupReconciler{Scheme: scheme, Recorder: recorder}
+			cronJob, err := r.buildCronJob(backup, nil)
+			if err != nil {
+				t.Fatalf("buildCronJob failed: %v", err)
+			}
+			tt.edit(cronJob)
+			r.Client = newTestClient(t, backup, cronJob)
+
+			existing := &batchv1.CronJob{}
+			if err := r.Get(context.Background(), client.ObjectKeyFromObject(cronJob), existing); err != nil {
+				t.Fatalf("failed to get CronJob: %v", err)
+			}
+			version := existing.ResourceVersion
+			if err := r.repairCronJob(context.Background(), backup, existing, nil); err != nil {
+				t.Fatalf("repairCronJob failed: %v", err)
+			}
+
+			got := &batchv1.CronJob{}
+			if err := r.Get(context.Background(), client.ObjectKeyFromObject(cronJob), got); err != nil {
+				t.Fatalf("failed to get CronJob: %v", err)
+			}
+			if updated := got.ResourceVersion != version; updated != tt.updated {
+				t.Errorf("updated = %v, want %v", updated, tt.updated)
+			}
+			if got.Spec.Schedule != "0 2 * * *" || got.Labels[BackupLabel] != "app" {
+				t.Errorf("CronJob has schedule %q and labels %v after the repair", got.Spec.Schedule, got.Labels)
+			}
+			if event := len(recorder.Events) > 0; event != tt.event {
+				t.Errorf("DriftCorrected event = %v, want %v", event, tt.event)
+			}
+		})
+	}
+}
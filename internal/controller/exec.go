@@ -0,0 +1,328 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+	"github.com/gobackup/gobackup-operator/pkg/k8sutil"
+)
+
+// The RBAC of exec dumps is not generated from markers: it lets the operator
+// grant pods/exec, and is only installed when exec dumps are enabled, with
+// config/rbac/exec_dumps_role.yaml or the execDumps.enabled chart value.
+
+const (
+	// ExecPodRefreshInterval is how often the pods of exec-mode Databases are
+	// listed again, so the Role of their Backup follows them
+	ExecPodRefreshInterval = time.Minute
+
+	// ReasonExecDumpSkipped is the reason of the Event emitted when an
+	// exec-mode Database of a Backup cannot be dumped
+	ReasonExecDumpSkipped = "ExecDumpSkipped"
+)
+
+// execDumpScript runs in the init containers of backup Jobs. It picks the
+// first running pod of $PODS, the pods the Role of the Job allows exec into,
+// and streams the stdout of the command given as arguments, run in that pod,
+// into $DUMP_FILE.
+const execDumpScript = `set -e
+for pod in $PODS; do
+  phase=$(kubectl get pod -n "$NAMESPACE" "$pod" -o jsonpath='{.status.phase}' 2>/dev/null || true)
+  if [ "$phase" = Running ]; then
+    echo "dumping from pod $pod" >&2
+    exec kubectl exec -n "$NAMESPACE" "$pod" ${CONTAINER:+-c "$CONTAINER"} -- "$@" > "$DUMP_FILE"
+  fi
+done
+echo "no running pod among $PODS matching $SELECTOR" >&2
+exit 1
+`
+
+// execDumpsEnabled reports whether the operator may dump Databases through
+// pods/exec. It is off unless ENABLE_EXEC_DUMPS is "true", since it grants
+// the Jobs of Backups exec into the pods their Databases select.
+func execDumpsEnabled() bool {
+	return os.Getenv("ENABLE_EXEC_DUMPS") == "true"
+}
+
+// execImage returns the image, with kubectl and a shell, of the init
+// containers that dump exec-mode Databases
+func execImage() string {
+	if image := os.Getenv("EXEC_DUMP_IMAGE"); image != "" {
+		return image
+	}
+	return "alpine/k8s:1.35.0"
+}
+
+// execServiceAccountName is the ServiceAccount of the Jobs of a Backup with
+// exec-mode Databases, allowed to exec into pods of its namespace
+func execServiceAccountName(backup *backupv1.Backup) string {
+	return backup.Name + "-exec"
+}
+
+// applyExecDumps adds an init container per Database recorded in
+// status.execDatabases that can be dumped. The dumps are written to the
+// workspace, if any, or to an emptyDir, mounted at k8sutil.ExecDumpPath in
+// every container.
+func applyExecDumps(spec *corev1.PodSpec, backup *backupv1.Backup) {
+	if len(backup.Status.ExecDatabases) == 0 {
+		return
+	}
+
+	mount := corev1.VolumeMount{Name: "dumps", MountPath: k8sutil.ExecDumpPath}
+	if backup.Spec.Workspace != nil {
+		mount.Name = "workspace"
+		mount.SubPath = "dumps"
+	} else {
+		spec.Volumes = append(spec.Volumes, corev1.Volume{
+			Name:         "dumps",
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+	}
+	spec.Containers[0].VolumeMounts = append(spec.Containers[0].VolumeMounts, mount)
+	spec.ServiceAccountName = execServiceAccountName(backup)
+
+	defaults := jobDefaults()
+	for i, database := range backup.Status.ExecDatabases {
+		if database.Message != "" {
+			continue
+		}
+		spec.InitContainers = append(spec.InitContainers, corev1.Container{
+			Name:            fmt.Sprintf("dump-%d", i),
+			Image:           execImage(),
+			ImagePullPolicy: defaults.ImagePullPolicy,
+			Command:         append([]string{"/bin/sh", "-c", execDumpScript, "--"}, database.Command...),
+			Env: []corev1.EnvVar{
+				{
+					Name:      "NAMESPACE",
+					ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.namespace"}},
+				},
+				{Name: "SELECTOR", Value: database.Selector},
+				{Name: "PODS", Value: strings.Join(database.Pods, " ")},
+				{Name: "CONTAINER", Value: database.Container},
+				{Name: "DUMP_FILE", Value: path.Join(k8sutil.ExecDumpPath, database.Database)},
+			},
+			VolumeMounts:    []corev1.VolumeMount{mount},
+			SecurityContext: defaults.SecurityContext,
+		})
+	}
+}
+
+// reconcileExecDatabases records the exec-mode Databases of a Backup, with
+// the pods they select, in status.execDatabases and maintains the
+// ServiceAccount their Jobs run as. It returns when to list the pods again,
+// or 0 for Backups without exec-mode Databases.
+func (r *BackupReconciler) reconcileExecDatabases(ctx context.Context, backup *backupv1.Backup) (time.Duration, error) {
+	var databases []backupv1.ExecDatabaseStatus
+	for _, ref := range backup.Spec.DatabaseRefs {
+		database := &backupv1.Database{}
+		if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: backup.Namespace}, database); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return 0, fmt.Errorf("failed to get database %s: %w", ref.Name, err)
+		}
+		if database.Spec.Exec == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(&database.Spec.Exec.Selector)
+		if err != nil {
+			return 0, fmt.Errorf("invalid exec selector of database %s: %w", database.Name, err)
+		}
+		status := backupv1.ExecDatabaseStatus{
+			Database:  database.Name,
+			Selector:  selector.String(),
+			Container: database.Spec.Exec.Container,
+			Command:   execCommand(database),
+		}
+		if execDumpsEnabled() {
+			// Pods are listed uncached, to avoid caching every pod of the cluster
+			pods, err := r.Clientset.CoreV1().Pods(backup.Namespace).List(ctx, metav1.ListOptions{LabelSelector: status.Selector})
+			if err != nil {
+				return 0, fmt.Errorf("failed to list pods of database %s: %w", database.Name, err)
+			}
+			for _, pod := range pods.Items {
+				status.Pods = append(status.Pods, pod.Name)
+			}
+			slices.Sort(status.Pods)
+			if len(status.Pods) == 0 {
+				status.Message = "no pod matches the exec selector"
+			}
+		} else {
+			status.Message = "exec dumps are disabled; set ENABLE_EXEC_DUMPS=true on the operator"
+		}
+		databases = append(databases, status)
+	}
+
+	if execDumpsEnabled() && len(databases) > 0 {
+		if err := r.applyExecAccess(ctx, backup, databases); err != nil {
+			return 0, err
+		}
+	} else if len(backup.Status.ExecDatabases) > 0 {
+		if err := r.deleteExecAccess(ctx, backup); err != nil {
+			return 0, err
+		}
+	}
+
+	var untilRefresh time.Duration
+	if len(databases) > 0 {
+		untilRefresh = ExecPodRefreshInterval
+	}
+	if equality.Semantic.DeepEqual(backup.Status.ExecDatabases, databases) {
+		return untilRefresh, nil
+	}
+	for _, database := range databases {
+		if database.Message != "" && !slices.ContainsFunc(backup.Status.ExecDatabases, func(previous backupv1.ExecDatabaseStatus) bool {
+			return previous.Database == database.Database && previous.Message == database.Message
+		}) {
+			r.Recorder.Eventf(backup, nil, corev1.EventTypeWarning, ReasonExecDumpSkipped, "DumpDatabase",
+				"Database %s is not dumped: %s", database.Database, database.Message)
+		}
+	}
+	patch := client.MergeFrom(backup.DeepCopy())
+	backup.Status.ExecDatabases = databases
+	if err := r.Status().Patch(ctx, backup, patch); err != nil {
+		return 0, fmt.Errorf("failed to update exec database status: %w", err)
+	}
+	return untilRefresh, nil
+}
+
+// execCommand returns spec.exec.command of a Database, or the default
+// command of its type
+func execCommand(database *backupv1.Database) []string {
+	if len(database.Spec.Exec.Command) > 0 {
+		return database.Spec.Exec.Command
+	}
+	config := database.Spec.Config
+	switch database.Spec.Type {
+	case "postgresql":
+		user, name := `"${POSTGRES_USER:-postgres}"`, `"${POSTGRES_DB:-${POSTGRES_USER:-postgres}}"`
+		if config.Username != nil {
+			user = shellQuote(*config.Username)
+		}
+		if config.Database != nil {
+			name = shellQuote(*config.Database)
+		}
+		return []string{"sh", "-c", "exec pg_dump -U " + user + " " + name}
+	case "mysql", "mariadb":
+		dump := "mysqldump"
+		if database.Spec.Type == "mariadb" {
+			dump = "mariadb-dump"
+		}
+		name := "--all-databases"
+		if config.Database != nil {
+			name = shellQuote(*config.Database)
+		}
+		return []string{"sh", "-c", `MYSQL_PWD="${MYSQL_ROOT_PASSWORD:-$MARIADB_ROOT_PASSWORD}" exec ` + dump + " -u root " + name}
+	case "mongodb":
+		command := []string{"mongodump", "--archive"}
+		if config.Database != nil {
+			command = append(command, "--db", *config.Database)
+		}
+		return command
+	case "redis":
+		return []string{"redis-cli", "--rdb", "-"}
+	}
+	return nil
+}
+
+// shellQuote quotes s for sh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// applyExecAccess creates or updates the ServiceAccount of the Jobs of a
+// Backup with exec-mode Databases, and its Role and RoleBinding. The Role
+// only allows exec into the pods recorded for the Databases.
+func (r *BackupReconciler) applyExecAccess(ctx context.Context, backup *backupv1.Backup, databases []backupv1.ExecDatabaseStatus) error {
+	name := execServiceAccountName(backup)
+	labels := map[string]string{BackupLabel: backup.Name}
+	meta := metav1.ObjectMeta{Name: name, Namespace: backup.Namespace}
+
+	account := &corev1.ServiceAccount{ObjectMeta: meta}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, account, func() error {
+		account.Labels = labels
+		return controllerutil.SetControllerReference(backup, account, r.Scheme)
+	}); err != nil {
+		return fmt.Errorf("failed to apply ServiceAccount %s: %w", name, err)
+	}
+
+	role := &rbacv1.Role{ObjectMeta: meta}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, role, func() error {
+		role.Labels = labels
+		var pods []string
+		for _, database := range databases {
+			pods = append(pods, database.Pods...)
+		}
+		slices.Sort(pods)
+		pods = slices.Compact(pods)
+		// Rules without resourceNames would cover every pod
+		role.Rules = nil
+		if len(pods) > 0 {
+			role.Rules = []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"pods"}, ResourceNames: pods, Verbs: []string{"get"}},
+				{APIGroups: []string{""}, Resources: []string{"pods/exec"}, ResourceNames: pods, Verbs: []string{"create"}},
+			}
+		}
+		return controllerutil.SetControllerReference(backup, role, r.Scheme)
+	}); err != nil {
+		return fmt.Errorf("failed to apply Role %s: %w", name, err)
+	}
+
+	binding := &rbacv1.RoleBinding{ObjectMeta: meta}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, binding, func() error {
+		binding.Labels = labels
+		binding.RoleRef = rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: name}
+		binding.Subjects = []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: name, Namespace: backup.Namespace}}
+		return controllerutil.SetControllerReference(backup, binding, r.Scheme)
+	}); err != nil {
+		return fmt.Errorf("failed to apply RoleBinding %s: %w", name, err)
+	}
+	return nil
+}
+
+// deleteExecAccess removes the objects created by applyExecAccess once a
+// Backup has no exec-mode Database left
+func (r *BackupReconciler) deleteExecAccess(ctx context.Context, backup *backupv1.Backup) error {
+	meta := metav1.ObjectMeta{Name: execServiceAccountName(backup), Namespace: backup.Namespace}
+	for _, obj := range []client.Object{
+		&rbacv1.RoleBinding{ObjectMeta: meta},
+		&rbacv1.Role{ObjectMeta: meta},
+		&corev1.ServiceAccount{ObjectMeta: meta},
+	} {
+		if err := r.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete %T %s: %w", obj, meta.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+)
+
+// applyJobTemplate applies spec.jobTemplate to a generated Job template: its
+// labels and annotations go to the Job and its pods, and its spec is merged
+// into the PodSpec as a strategic merge patch
+func applyJobTemplate(template *batchv1.JobTemplateSpec, overlay *backupv1.BackupJobTemplate) error {
+	if overlay == nil {
+		return nil
+	}
+
+	// Pods carry the operator's labels too, so user labels cannot claim
+	// them for another Backup
+	template.Labels = mergeMetadata(overlay.Metadata.Labels, template.Labels)
+	template.Annotations = mergeMetadata(overlay.Metadata.Annotations, template.Annotations)
+	pod := &template.Spec.Template
+	pod.Labels = mergeMetadata(template.Labels, pod.Labels)
+	pod.Annotations = mergeMetadata(overlay.Metadata.Annotations, pod.Annotations)
+
+	if overlay.Spec == nil || len(overlay.Spec.Raw) == 0 {
+		return nil
+	}
+	original, err := json.Marshal(pod.Spec)
+	if err != nil {
+		return fmt.Errorf("failed to encode generated pod spec: %w", err)
+	}
+	merged, err := strategicpatch.StrategicMergePatch(original, overlay.Spec.Raw, corev1.PodSpec{})
+	if err != nil {
+		return fmt.Errorf("invalid spec.jobTemplate.spec: %w", err)
+	}
+	spec := corev1.PodSpec{}
+	if err := json.Unmarshal(merged, &spec); err != nil {
+		return fmt.Errorf("invalid spec.jobTemplate.spec: %w", err)
+	}
+	pod.Spec = spec
+	return nil
+}
+
+// templateAnnotations returns the annotations of spec.jobTemplate
+func templateAnnotations(backup *backupv1.Backup) map[string]string {
+	if backup.Spec.JobTemplate == nil {
+		return nil
+	}
+	return backup.Spec.JobTemplate.Metadata.Annotations
+}
+
+// mergeMetadata returns the labels or annotations of spec.jobTemplate with
+// those of the operator on top, or nil if there are none
+func mergeMetadata(user, operator map[string]string) map[string]string {
+	if len(user) == 0 {
+		return operator
+	}
+	merged := make(map[string]string, len(user)+len(operator))
+	for k, v := range user {
+		merged[k] = v
+	}
+	for k, v := range operator {
+		merged[k] = v
+	}
+	return merged
+}
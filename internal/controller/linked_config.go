@@ -0,0 +1,99 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+)
+
+const (
+	// LinkedConfigRefreshInterval is how often the Secret of a Backup is
+	// re-rendered when its Databases take settings from other objects
+	LinkedConfigRefreshInterval = 5 * time.Minute
+
+	// ReasonConfigRefreshed is the reason of the Event emitted when the
+	// objects linked by the Databases of a Backup changed its configuration
+	ReasonConfigRefreshed = "ConfigRefreshed"
+
+	// ReasonConfigRefreshFailed is the reason of the Event emitted when the
+	// objects linked by the Databases of a Backup cannot be resolved
+	ReasonConfigRefreshFailed = "ConfigRefreshFailed"
+)
+
+// hasLinkedDatabases reports whether a Database of the Backup is resolved
+// from spec.config.serviceRef or spec.config.fromObject
+func (r *BackupReconciler) hasLinkedDatabases(ctx context.Context, backup *backupv1.Backup) (bool, error) {
+	for _, ref := range backup.Spec.DatabaseRefs {
+		database := &backupv1.Database{}
+		if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: backup.Namespace}, database); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return false, fmt.Errorf("failed to get database %s: %w", ref.Name, err)
+		}
+		if database.Spec.Exec == nil && (database.Spec.Config.ServiceRef != nil || database.Spec.Config.FromObject != nil) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// refreshLinkedConfig re-renders the Secret of a Backup with linked
+// Databases, so runs follow the Services and objects they are resolved from.
+// It returns when to refresh again, or 0 for Backups without linked
+// Databases.
+func (r *BackupReconciler) refreshLinkedConfig(ctx context.Context, backup *backupv1.Backup) (time.Duration, error) {
+	if backup.Status.ObservedGeneration != backup.Generation {
+		return 0, nil
+	}
+	linked, err := r.hasLinkedDatabases(ctx, backup)
+	if err != nil || !linked {
+		return 0, err
+	}
+
+	configHash, err := r.K8s.CreateSecret(ctx, backup)
+	if err != nil {
+		// Runs keep the last rendered configuration until the objects resolve
+		log.FromContext(ctx).Error(err, "Failed to refresh linked configuration", "name", backup.Name)
+		r.Recorder.Eventf(backup, nil, corev1.EventTypeWarning, ReasonConfigRefreshFailed, "RenderSecret",
+			"Failed to re-render Secret %s: %v", backup.Name, err)
+		return LinkedConfigRefreshInterval, nil
+	}
+	if configHash == backup.Status.ConfigHash {
+		return LinkedConfigRefreshInterval, nil
+	}
+
+	patch := client.MergeFrom(backup.DeepCopy())
+	backup.Status.ConfigHash = configHash
+	if err := r.Status().Patch(ctx, backup, patch); err != nil {
+		return 0, fmt.Errorf("failed to record config hash: %w", err)
+	}
+	log.FromContext(ctx).Info("Refreshed linked configuration", "name", backup.Name)
+	r.Recorder.Eventf(backup, nil, corev1.EventTypeNormal, ReasonConfigRefreshed, "RenderSecret",
+		"Secret %s re-rendered from the objects its Databases are resolved from", backup.Name)
+	return LinkedConfigRefreshInterval, nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+)
+
+// +kubebuilder:rbac:groups=gobackup.io,resources=operatorconfigs,verbs=get;list;watch
+
+// loadedJobDefaults holds the job defaults of the OperatorConfig, as last
+// read by refreshJobDefaults
+var loadedJobDefaults atomic.Pointer[backupv1.JobDefaults]
+
+// refreshJobDefaults reads the job defaults from the OperatorConfig named
+// default. Reconcilers call it first, so edits apply without a restart.
+// Without an OperatorConfig the built-in defaults apply.
+func refreshJobDefaults(ctx context.Context, c client.Reader) error {
+	config := &backupv1.OperatorConfig{}
+	err := c.Get(ctx, types.NamespacedName{Name: backupv1.OperatorConfigName}, config)
+	if errors.IsNotFound(err) {
+		loadedJobDefaults.Store(&backupv1.JobDefaults{})
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get operator config: %w", err)
+	}
+	loadedJobDefaults.Store(&config.Spec.JobDefaults)
+	return nil
+}
+
+// jobDefaults returns the job defaults of the OperatorConfig, with the
+// built-in defaults filled in
+func jobDefaults() backupv1.JobDefaults {
+	defaults := backupv1.JobDefaults{}
+	if loaded := loadedJobDefaults.Load(); loaded != nil {
+		defaults = *loaded.DeepCopy()
+	}
+
+	if defaults.Image == "" {
+		defaults.Image = gobackupImage()
+	}
+	if defaults.ImagePullPolicy == "" {
+		defaults.ImagePullPolicy = corev1.PullIfNotPresent
+	}
+	if defaults.ImagePullSecrets == nil {
+		defaults.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "regcred"}}
+	}
+	if defaults.TTLSecondsAfterFinished == nil {
+		ttl := int32(60)
+		defaults.TTLSecondsAfterFinished = &ttl
+	}
+	if defaults.SuccessfulJobsHistoryLimit == nil {
+		limit := int32(3)
+		defaults.SuccessfulJobsHistoryLimit = &limit
+	}
+	if defaults.FailedJobsHistoryLimit == nil {
+		limit := int32(1)
+		defaults.FailedJobsHistoryLimit = &limit
+	}
+	if defaults.LogTailLines == nil {
+		lines := int64(100)
+		defaults.LogTailLines = &lines
+	}
+	return defaults
+}
+
+// findAllBackups maps the OperatorConfig to every Backup, so their CronJobs
+// are re-rendered with the new defaults
+func (r *BackupReconciler) findAllBackups(ctx context.Context, obj client.Object) []ctrl.Request {
+	if obj.GetName() != backupv1.OperatorConfigName {
+		return nil
+	}
+
+	backups := &backupv1.BackupList{}
+	if err := r.List(ctx, backups); err != nil {
+		return nil
+	}
+	requests := make([]ctrl.Request, 0, len(backups.Items))
+	for _, backup := range backups.Items {
+		requests = append(requests, ctrl.Request{NamespacedName: types.NamespacedName{Name: backup.Name, Namespace: backup.Namespace}})
+	}
+	return requests
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+)
+
+func TestRefreshJobDefaults(t *testing.T) {
+	t.Setenv("BACKUP_JOB_IMAGE", "")
+	previous := loadedJobDefaults.Load()
+	t.Cleanup(func() { loadedJobDefaults.Store(previous) })
+
+	ttl := int32(600)
+	tests := []struct {
+		name        string
+		config      *backupv1.OperatorConfig
+		wantImage   string
+		wantPolicy  corev1.PullPolicy
+		wantTTL     int32
+		wantSecrets []corev1.LocalObjectReference
+	}{
+		{
+			name:        "built-in defaults",
+			wantImage:   "huacnlee/gobackup:latest",
+			wantPolicy:  corev1.PullIfNotPresent,
+			wantTTL:     60,
+			wantSecrets: []corev1.LocalObjectReference{{Name: "regcred"}},
+		},
+		{
+			name: "operator config",
+			config: &backupv1.OperatorConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: backupv1.OperatorConfigName},
+				Spec: backupv1.OperatorConfigSpec{JobDefaults: backupv1.JobDefaults{
+					Image: "registry.example.com/gobackup:2", TTLSecondsAfterFinished: &ttl,
+					ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+				}},
+			},
+			wantImage:   "registry.example.com/gobackup:2",
+			wantPolicy:  corev1.PullIfNotPresent,
+			wantTTL:     600,
+			wantSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+		},
+		{
+			name: "differently named operator config",
+			config: &backupv1.OperatorConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "custom"},
+				Spec:       backupv1.OperatorConfigSpec{JobDefaults: backupv1.JobDefaults{Image: "registry.example.com/gobackup:2"}},
+			},
+			wantImage:   "huacnlee/gobackup:latest",
+			wantPolicy:  corev1.PullIfNotPresent,
+			wantTTL:     60,
+			wantSecrets: []corev1.LocalObjectReference{{Name: "regcred"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var objs []client.Object
+			if tt.config != nil {
+				objs = append(objs, tt.config)
+			}
+			// A default left by an earlier OperatorConfig must not survive it
+			loadedJobDefaults.Store(&backupv1.JobDefaults{Image: "stale"})
+
+			if err := refreshJobDefaults(context.Background(), newTestClient(t, objs...)); err != nil {
+				t.Fatalf("refreshJobDefaults failed: %v", err)
+			}
+			defaults := jobDefaults()
+			if defaults.Image != tt.wantImage {
+				t.Errorf("image = %q, want %q", defaults.Image, tt.wantImage)
+			}
+			if defaults.ImagePullPolicy != tt.wantPolicy {
+				t.Errorf("imagePullPolicy = %q, want %q", defaults.ImagePullPolicy, tt.wantPolicy)
+			}
+			if *defaults.TTLSecondsAfterFinished != tt.wantTTL {
+				t.Errorf("ttlSecondsAfterFinished = %d, want %d", *defaults.TTLSecondsAfterFinished, tt.wantTTL)
+			}
+			if !slices.Equal(defaults.ImagePullSecrets, tt.wantSecrets) {
+				t.Errorf("imagePullSecrets = %v, want %v", defaults.ImagePullSecrets, tt.wantSecrets)
+			}
+		})
+	}
+}
+
+func TestFindAllBackups(t *testing.T) {
+	c := newTestClient(t,
+		&backupv1.Backup{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"}},
+		&backupv1.Backup{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "other"}},
+	)
+	r := &BackupReconciler{Client: c, Scheme: c.Scheme()}
+
+	tests := []struct {
+		name   string
+		config string
+		want   []string
+	}{
+		{name: "operator config", config: backupv1.OperatorConfigName, want: []string{"app", "db"}},
+		{name: "differently named operator config", config: "custom", want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &backupv1.OperatorConfig{ObjectMeta: metav1.ObjectMeta{Name: tt.config}}
+			if got := requestNames(r.findAllBackups(context.Background(), config)); !slices.Equal(got, tt.want) {
+				t.Errorf("findAllBackups = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
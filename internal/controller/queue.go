@@ -0,0 +1,391 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+)
+
+const (
+	// QueuedAnnotation marks a backup Job created suspended, waiting for the
+	// queue to admit it. Its value is why the Job is still waiting.
+	QueuedAnnotation = "gobackup.io/queued"
+
+	// QueueRecheckInterval is how often queued Jobs are reconsidered when no
+	// Job event arrives
+	QueueRecheckInterval = 30 * time.Second
+)
+
+// queueRequest is the only request of the BackupQueueReconciler: every Job
+// event triggers one admission pass over the whole queue
+var queueRequest = ctrl.Request{NamespacedName: types.NamespacedName{Name: "backup-queue"}}
+
+// QueueLimits caps the number of backup Jobs running at once. Zero means
+// unlimited; the queue is disabled when every limit is zero.
+type QueueLimits struct {
+	// Cluster caps running backups in the whole cluster
+	Cluster int
+	// Namespace caps running backups per namespace
+	Namespace int
+	// Storage caps running backups uploading to the same Storage
+	Storage int
+	// DatabaseHost caps running backups dumping from the same database host
+	DatabaseHost int
+}
+
+// Enabled reports whether any limit is set
+func (l QueueLimits) Enabled() bool {
+	return l.Cluster > 0 || l.Namespace > 0 || l.Storage > 0 || l.DatabaseHost > 0
+}
+
+// LoadQueueLimits reads the queue limits from the BACKUP_QUEUE_MAX_CONCURRENT,
+// BACKUP_QUEUE_MAX_PER_NAMESPACE, BACKUP_QUEUE_MAX_PER_STORAGE and
+// BACKUP_QUEUE_MAX_PER_DATABASE_HOST env vars
+func LoadQueueLimits() (QueueLimits, error) {
+	var limits QueueLimits
+	for env, limit := range map[string]*int{
+		"BACKUP_QUEUE_MAX_CONCURRENT":        &limits.Cluster,
+		"BACKUP_QUEUE_MAX_PER_NAMESPACE":     &limits.Namespace,
+		"BACKUP_QUEUE_MAX_PER_STORAGE":       &limits.Storage,
+		"BACKUP_QUEUE_MAX_PER_DATABASE_HOST": &limits.DatabaseHost,
+	} {
+		value := strings.TrimSpace(os.Getenv(env))
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return QueueLimits{}, fmt.Errorf("invalid %s %q: must be a non-negative integer", env, value)
+		}
+		*limit = n
+	}
+	return limits, nil
+}
+
+// queueEnabled reports whether backup Jobs must wait for the queue. main
+// refuses to start with invalid limits, so errors are not expected here.
+func queueEnabled() bool {
+	limits, err := LoadQueueLimits()
+	return err == nil && limits.Enabled()
+}
+
+// BackupQueueReconciler admits queued backup Jobs. Backup Jobs are created
+// suspended while the queue is enabled; they are resumed in priority order
+// as long as no limit is exceeded. Without limits, every queued Job is resumed.
+type BackupQueueReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	Limits QueueLimits
+
+	mu sync.Mutex
+	// admitted holds Jobs resumed by earlier passes that the cache may still
+	// show as suspended, so they are not admitted twice or left uncounted
+	admitted map[types.UID]bool
+}
+
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=gobackup.io,resources=backups,verbs=get;list;watch
+// +kubebuilder:rbac:groups=gobackup.io,resources=databases,verbs=get;list;watch
+
+// queuedJob is a backup Job with what it competes for
+type queuedJob struct {
+	job      *batchv1.Job
+	priority int32
+	storages []string
+	hosts    []string
+}
+
+// Reconcile runs one admission pass: it counts running backup Jobs per limit
+// and resumes queued Jobs, highest priority and oldest first. A Job blocked by
+// one limit does not hold back lower priority Jobs that fit.
+func (r *BackupQueueReconciler) Reconcile(ctx context.Context, _ ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.admitted == nil {
+		r.admitted = map[types.UID]bool{}
+	}
+
+	jobList := &batchv1.JobList{}
+	if err := r.List(ctx, jobList, client.HasLabels{BackupLabel}); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list backup jobs: %w", err)
+	}
+
+	backups := map[types.NamespacedName]*backupv1.Backup{}
+	running := newQueueUsage()
+	var queued []queuedJob
+	seen := map[types.UID]bool{}
+	for i := range jobList.Items {
+		job := &jobList.Items[i]
+		seen[job.UID] = true
+		if jobFinished(job) {
+			delete(r.admitted, job.UID)
+			continue
+		}
+
+		suspended := job.Spec.Suspend != nil && *job.Spec.Suspend
+		if !suspended {
+			delete(r.admitted, job.UID)
+		}
+		if suspended && !r.admitted[job.UID] && !isQueued(job) {
+			// Suspended by someone else; it neither runs nor waits for us
+			continue
+		}
+
+		entry, err := r.describeJob(ctx, job, backups)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if suspended && !r.admitted[job.UID] {
+			queued = append(queued, entry)
+		} else {
+			running.add(entry)
+		}
+	}
+	for uid := range r.admitted {
+		if !seen[uid] {
+			delete(r.admitted, uid)
+		}
+	}
+
+	sort.SliceStable(queued, func(i, j int) bool {
+		a, b := queued[i], queued[j]
+		if a.priority != b.priority {
+			return a.priority > b.priority
+		}
+		if !a.job.CreationTimestamp.Equal(&b.job.CreationTimestamp) {
+			return a.job.CreationTimestamp.Before(&b.job.CreationTimestamp)
+		}
+		return a.job.Name < b.job.Name
+	})
+
+	for _, entry := range queued {
+		reason := running.blockedBy(r.Limits, entry)
+		if reason != "" {
+			if err := r.setQueuedReason(ctx, entry.job, reason); err != nil {
+				return ctrl.Result{}, err
+			}
+			continue
+		}
+
+		if err := r.admit(ctx, entry.job); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.admitted[entry.job.UID] = true
+		running.add(entry)
+		logger.Info("Admitted queued backup job", "namespace", entry.job.Namespace, "job", entry.job.Name, "priority", entry.priority)
+	}
+
+	if len(queued) > 0 {
+		return ctrl.Result{RequeueAfter: QueueRecheckInterval}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// describeJob looks up the priority, Storages and database hosts of a Job's
+// Backup. Jobs whose Backup is gone compete for the cluster and namespace
+// limits only.
+func (r *BackupQueueReconciler) describeJob(ctx context.Context, job *batchv1.Job, backups map[types.NamespacedName]*backupv1.Backup) (queuedJob, error) {
+	entry := queuedJob{job: job}
+
+	key := types.NamespacedName{Namespace: job.Namespace, Name: job.Labels[BackupLabel]}
+	backup, ok := backups[key]
+	if !ok {
+		backup = &backupv1.Backup{}
+		if err := r.Get(ctx, key, backup); client.IgnoreNotFound(err) != nil {
+			return entry, fmt.Errorf("failed to get backup %s: %w", key, err)
+		} else if err != nil {
+			backup = nil
+		}
+		backups[key] = backup
+	}
+	if backup == nil {
+		return entry, nil
+	}
+
+	if backup.Spec.Priority != nil {
+		entry.priority = *backup.Spec.Priority
+	}
+
+	var tier *backupv1.BackupScheduleTier
+	if name := job.Labels[TierLabel]; name != "" {
+		tier = findScheduleTier(backup, name)
+	}
+	for _, ref := range backup.Spec.StorageRefs {
+		if tier != nil && len(tier.StorageRefs) > 0 && !slices.Contains(tier.StorageRefs, ref.Name) {
+			continue
+		}
+		entry.storages = append(entry.storages, job.Namespace+"/"+ref.Name)
+	}
+
+	for _, ref := range backup.Spec.DatabaseRefs {
+		database := &backupv1.Database{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: job.Namespace, Name: ref.Name}, database); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return entry, fmt.Errorf("failed to get database %s: %w", ref.Name, err)
+			}
+			continue
+		}
+		if host := databaseHost(database); host != "" {
+			entry.hosts = append(entry.hosts, host)
+		}
+	}
+	return entry, nil
+}
+
+// admit resumes a queued Job
+func (r *BackupQueueReconciler) admit(ctx context.Context, job *batchv1.Job) error {
+	patch := client.MergeFrom(job.DeepCopy())
+	suspend := false
+	job.Spec.Suspend = &suspend
+	delete(job.Annotations, QueuedAnnotation)
+	if err := r.Patch(ctx, job, patch); err != nil {
+		return fmt.Errorf("failed to resume job %s: %w", job.Name, err)
+	}
+	return nil
+}
+
+// setQueuedReason records why a Job is still queued, for its run status
+func (r *BackupQueueReconciler) setQueuedReason(ctx context.Context, job *batchv1.Job, reason string) error {
+	if job.Annotations[QueuedAnnotation] == reason {
+		return nil
+	}
+	patch := client.MergeFrom(job.DeepCopy())
+	job.Annotations[QueuedAnnotation] = reason
+	if err := r.Patch(ctx, job, patch); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to update queued job %s: %w", job.Name, err)
+	}
+	return nil
+}
+
+// queueUsage counts running backup Jobs per limited resource
+type queueUsage struct {
+	cluster    int
+	namespaces map[string]int
+	storages   map[string]int
+	hosts      map[string]int
+}
+
+func newQueueUsage() *queueUsage {
+	return &queueUsage{
+		namespaces: map[string]int{},
+		storages:   map[string]int{},
+		hosts:      map[string]int{},
+	}
+}
+
+func (u *queueUsage) add(entry queuedJob) {
+	u.cluster++
+	u.namespaces[entry.job.Namespace]++
+	for _, storage := range entry.storages {
+		u.storages[storage]++
+	}
+	for _, host := range entry.hosts {
+		u.hosts[host]++
+	}
+}
+
+// blockedBy returns which limit keeps a Job queued, or "" if it may run
+func (u *queueUsage) blockedBy(limits QueueLimits, entry queuedJob) string {
+	if limits.Cluster > 0 && u.cluster >= limits.Cluster {
+		return fmt.Sprintf("cluster limit of %d running backups reached", limits.Cluster)
+	}
+	if limits.Namespace > 0 && u.namespaces[entry.job.Namespace] >= limits.Namespace {
+		return fmt.Sprintf("limit of %d running backups in namespace %s reached", limits.Namespace, entry.job.Namespace)
+	}
+	if limits.Storage > 0 {
+		for _, storage := range entry.storages {
+			if u.storages[storage] >= limits.Storage {
+				return fmt.Sprintf("limit of %d running backups for storage %s reached", limits.Storage, storage)
+			}
+		}
+	}
+	if limits.DatabaseHost > 0 {
+		for _, host := range entry.hosts {
+			if u.hosts[host] >= limits.DatabaseHost {
+				return fmt.Sprintf("limit of %d running backups for database host %s reached", limits.DatabaseHost, host)
+			}
+		}
+	}
+	return ""
+}
+
+// databaseHost returns the host[:port] a Database is dumped from, or "" for
+// databases reached through a socket
+func databaseHost(database *backupv1.Database) string {
+	config := database.Spec.Config
+	if target := database.Status.ServiceTarget; config.ServiceRef != nil && target != nil {
+		return fmt.Sprintf("%s:%d", strings.ToLower(target.Host), target.Port)
+	}
+	if config.Host == nil || *config.Host == "" {
+		return ""
+	}
+	host := strings.ToLower(*config.Host)
+	if config.Port != nil {
+		host = fmt.Sprintf("%s:%d", host, *config.Port)
+	}
+	return host
+}
+
+// isQueued reports whether a Job is waiting for the queue
+func isQueued(job *batchv1.Job) bool {
+	_, ok := job.Annotations[QueuedAnnotation]
+	return ok && job.Spec.Suspend != nil && *job.Spec.Suspend
+}
+
+// jobFinished reports whether a Job completed or failed for good
+func jobFinished(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if (condition.Type == batchv1.JobComplete || condition.Type == batchv1.JobFailed) && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *BackupQueueReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("backupqueue").
+		Watches(&batchv1.Job{}, handler.EnqueueRequestsFromMapFunc(func(_ context.Context, obj client.Object) []ctrl.Request {
+			if _, ok := obj.GetLabels()[BackupLabel]; !ok {
+				return nil
+			}
+			return []ctrl.Request{queueRequest}
+		})).
+		Complete(r)
+}
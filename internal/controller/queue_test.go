@@ -0,0 +1,165 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+)
+
+// testQueueJob returns a backup Job of backup created at minute of the hour,
+// queued or suspended by someone else when suspended is set, or running
+func testQueueJob(name, backup string, minute int, suspended, queued bool) *batchv1.Job {
+	job := testJob(name, map[string]string{BackupLabel: backup}, nil, "", "")
+	job.UID = types.UID(name)
+	job.CreationTimestamp = metav1.NewTime(time.Date(2024, 6, 15, 1, minute, 0, 0, time.UTC))
+	job.Spec.Suspend = &suspended
+	if queued {
+		job.Annotations = map[string]string{QueuedAnnotation: "waiting for admission"}
+	}
+	return job
+}
+
+func TestBackupQueueReconcile(t *testing.T) {
+	priority := int32(10)
+	host := "db.example.com"
+	backups := []client.Object{
+		&backupv1.Backup{
+			ObjectMeta: metav1.ObjectMeta{Name: "high", Namespace: "default"},
+			Spec:       backupv1.BackupSpec{Priority: &priority, StorageRefs: []backupv1.StorageRef{{Name: "s3"}}},
+		},
+		testBackup("low", []string{"db"}, []string{"s3"}),
+		testBackup("other", []string{"replica"}, []string{"local"}),
+		&backupv1.Database{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+			Spec:       backupv1.DatabaseSpec{Type: "postgresql", Config: backupv1.DatabaseConfig{Host: &host}},
+		},
+		&backupv1.Database{
+			ObjectMeta: metav1.ObjectMeta{Name: "replica", Namespace: "default"},
+			Spec:       backupv1.DatabaseSpec{Type: "postgresql", Config: backupv1.DatabaseConfig{Host: &host}},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		limits       QueueLimits
+		jobs         []client.Object
+		wantAdmitted []string
+		// wantQueued maps Jobs left queued to their reason
+		wantQueued map[string]string
+	}{
+		{
+			name:         "no limits",
+			jobs:         []client.Object{testQueueJob("low-1", "low", 1, true, true), testQueueJob("high-1", "high", 2, true, true)},
+			wantAdmitted: []string{"low-1", "high-1"},
+		},
+		{
+			name:   "cluster limit admits the highest priority first",
+			limits: QueueLimits{Cluster: 2},
+			jobs: []client.Object{
+				testQueueJob("other-1", "other", 0, false, false),
+				testQueueJob("low-1", "low", 1, true, true),
+				testQueueJob("high-1", "high", 2, true, true),
+			},
+			wantAdmitted: []string{"high-1"},
+			wantQueued:   map[string]string{"low-1": "cluster limit of 2 running backups reached"},
+		},
+		{
+			name:   "oldest first within a priority",
+			limits: QueueLimits{Namespace: 1},
+			jobs: []client.Object{
+				testQueueJob("low-2", "low", 2, true, true),
+				testQueueJob("low-1", "low", 1, true, true),
+			},
+			wantAdmitted: []string{"low-1"},
+			wantQueued:   map[string]string{"low-2": "limit of 1 running backups in namespace default reached"},
+		},
+		{
+			name:   "blocked job does not hold back others",
+			limits: QueueLimits{Storage: 1},
+			jobs: []client.Object{
+				testQueueJob("low-1", "low", 0, false, false),
+				testQueueJob("high-1", "high", 1, true, true),
+				testQueueJob("other-1", "other", 2, true, true),
+			},
+			wantAdmitted: []string{"other-1"},
+			wantQueued:   map[string]string{"high-1": "limit of 1 running backups for storage default/s3 reached"},
+		},
+		{
+			name:   "database host limit",
+			limits: QueueLimits{DatabaseHost: 1},
+			jobs: []client.Object{
+				testQueueJob("low-1", "low", 1, true, true),
+				testQueueJob("other-1", "other", 2, true, true),
+			},
+			wantAdmitted: []string{"low-1"},
+			wantQueued:   map[string]string{"other-1": "limit of 1 running backups for database host db.example.com reached"},
+		},
+		{
+			name:   "finished and foreign suspended jobs are not counted",
+			limits: QueueLimits{Cluster: 1},
+			jobs: []client.Object{
+				finished(testQueueJob("low-0", "low", 0, false, false), time.Now()),
+				testQueueJob("other-0", "other", 0, true, false),
+				testQueueJob("high-1", "high", 1, true, true),
+			},
+			wantAdmitted: []string{"high-1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, append(tt.jobs, backups...)...)
+			r := &BackupQueueReconciler{Client: c, Scheme: c.Scheme(), Limits: tt.limits}
+
+			result, err := r.Reconcile(context.Background(), queueRequest)
+			if err != nil {
+				t.Fatalf("Reconcile failed: %v", err)
+			}
+			if wantRequeue := len(tt.wantAdmitted) > 0 || len(tt.wantQueued) > 0; (result.RequeueAfter > 0) != wantRequeue {
+				t.Errorf("RequeueAfter = %v, want requeue %v", result.RequeueAfter, wantRequeue)
+			}
+
+			for _, name := range tt.wantAdmitted {
+				job := &batchv1.Job{}
+				if err := c.Get(context.Background(), types.NamespacedName{Name: name, Namespace: "default"}, job); err != nil {
+					t.Fatalf("failed to get job %s: %v", name, err)
+				}
+				if isQueued(job) || job.Spec.Suspend == nil || *job.Spec.Suspend {
+					t.Errorf("job %s was not admitted", name)
+				}
+			}
+			for name, reason := range tt.wantQueued {
+				job := &batchv1.Job{}
+				if err := c.Get(context.Background(), types.NamespacedName{Name: name, Namespace: "default"}, job); err != nil {
+					t.Fatalf("failed to get job %s: %v", name, err)
+				}
+				if !isQueued(job) || !strings.Contains(job.Annotations[QueuedAnnotation], reason) {
+					t.Errorf("job %s queued with %q, want %q", name, job.Annotations[QueuedAnnotation], reason)
+				}
+			}
+		})
+	}
+}
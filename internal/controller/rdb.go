@@ -0,0 +1,245 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+)
+
+// DefaultRdbPath is where gobackup reads the RDB file of a Redis server in
+// copy mode when rdb_path is not set
+const DefaultRdbPath = "/var/lib/redis/dump.rdb"
+
+// reservedMountPaths are used by backup Jobs for other volumes; RDB volumes
+// may not be mounted over or under them
+var reservedMountPaths = []string{"/root/.gobackup", WorkspaceMountPath, "/archives"}
+
+// applyRdbVolumes mounts the volumes recorded in status.rdbVolumes read-only
+// into the gobackup container of a pod, at the directory of rdb_path, and
+// schedules the pod next to the Redis pods using ReadWriteOnce claims
+func applyRdbVolumes(spec *corev1.PodSpec, backup *backupv1.Backup) {
+	container := &spec.Containers[0]
+	for i, volume := range backup.Status.RdbVolumes {
+		if volume.ClaimName == "" || volume.Message != "" {
+			continue
+		}
+		name := fmt.Sprintf("rdb-%d", i)
+		spec.Volumes = append(spec.Volumes, corev1.Volume{
+			Name: name,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: volume.ClaimName, ReadOnly: true},
+			},
+		})
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      name,
+			MountPath: volume.MountPath,
+			SubPath:   volume.SubPath,
+			ReadOnly:  true,
+		})
+		if volume.ReadWriteOnce {
+			requireNodeOf(spec, volume.MountedBy)
+		}
+	}
+}
+
+// reconcileRdbVolumes records in status.rdbVolumes the claims holding the
+// RDB files of the Redis Databases of a Backup with config.rdb_source
+func (r *BackupReconciler) reconcileRdbVolumes(ctx context.Context, backup *backupv1.Backup) error {
+	var volumes []backupv1.RdbVolumeStatus
+	for _, ref := range backup.Spec.DatabaseRefs {
+		database := &backupv1.Database{}
+		if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: backup.Namespace}, database); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to get database %s: %w", ref.Name, err)
+		}
+		source := database.Spec.Config.RdbSource
+		if database.Spec.Type != "redis" || source == nil {
+			continue
+		}
+
+		rdbPath := DefaultRdbPath
+		if database.Spec.Config.RdbPath != nil {
+			rdbPath = *database.Spec.Config.RdbPath
+		}
+		status := backupv1.RdbVolumeStatus{Database: database.Name, MountPath: path.Dir(path.Clean(rdbPath))}
+
+		var err error
+		if source.PodName != nil {
+			err = r.resolveRdbPod(ctx, backup.Namespace, *source.PodName, &status)
+		} else {
+			err = r.resolveRdbClaim(ctx, backup.Namespace, source, &status)
+		}
+		if err != nil {
+			return err
+		}
+
+		if status.Message == "" {
+			status.Message = rdbMountConflict(status.MountPath, volumes)
+		}
+		volumes = append(volumes, status)
+	}
+
+	if equality.Semantic.DeepEqual(backup.Status.RdbVolumes, volumes) {
+		return nil
+	}
+	patch := client.MergeFrom(backup.DeepCopy())
+	backup.Status.RdbVolumes = volumes
+	if err := r.Status().Patch(ctx, backup, patch); err != nil {
+		return fmt.Errorf("failed to update RDB volume status: %w", err)
+	}
+	return nil
+}
+
+// resolveRdbClaim fills status from the claim of rdb_source.claim_name
+func (r *BackupReconciler) resolveRdbClaim(ctx context.Context, namespace string, source *backupv1.RedisRdbSource, status *backupv1.RdbVolumeStatus) error {
+	status.ClaimName = *source.ClaimName
+	if source.SubPath != nil {
+		status.SubPath = cleanSubPath(*source.SubPath)
+	}
+
+	claim := &corev1.PersistentVolumeClaim{}
+	err := r.Get(ctx, types.NamespacedName{Name: status.ClaimName, Namespace: namespace}, claim)
+	if errors.IsNotFound(err) {
+		status.Message = "PersistentVolumeClaim not found"
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get PersistentVolumeClaim %s: %w", status.ClaimName, err)
+	}
+
+	status.ReadWriteOnce = readWriteOnce(claim)
+	if status.ReadWriteOnce {
+		pods := &corev1.PodList{}
+		if err := r.List(ctx, pods, client.InNamespace(namespace)); err != nil {
+			return fmt.Errorf("failed to list pods: %w", err)
+		}
+		status.MountedBy = claimConsumerLabels(pods.Items, status.ClaimName)
+	}
+	return nil
+}
+
+// resolveRdbPod fills status from the claim the pod of rdb_source.pod_name
+// mounts at or above the directory of rdb_path
+func (r *BackupReconciler) resolveRdbPod(ctx context.Context, namespace, podName string, status *backupv1.RdbVolumeStatus) error {
+	pod := &corev1.Pod{}
+	err := r.Get(ctx, types.NamespacedName{Name: podName, Namespace: namespace}, pod)
+	if errors.IsNotFound(err) {
+		status.Message = fmt.Sprintf("pod %s not found", podName)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get pod %s: %w", podName, err)
+	}
+
+	// The deepest mount containing the RDB directory holds the file
+	var mount *corev1.VolumeMount
+	for _, container := range pod.Spec.Containers {
+		for i := range container.VolumeMounts {
+			candidate := &container.VolumeMounts[i]
+			if !pathWithin(status.MountPath, candidate.MountPath) {
+				continue
+			}
+			if mount == nil || len(candidate.MountPath) > len(mount.MountPath) {
+				mount = candidate
+			}
+		}
+	}
+	if mount == nil {
+		status.Message = fmt.Sprintf("pod %s mounts no volume at %s", podName, status.MountPath)
+		return nil
+	}
+
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Name != mount.Name {
+			continue
+		}
+		switch {
+		case volume.PersistentVolumeClaim != nil:
+			status.ClaimName = volume.PersistentVolumeClaim.ClaimName
+		case volume.Ephemeral != nil:
+			// Generic ephemeral volumes are backed by a claim named
+			// <pod>-<volume>
+			status.ClaimName = pod.Name + "-" + volume.Name
+		default:
+			status.Message = fmt.Sprintf("volume %s of pod %s is not a PersistentVolumeClaim", volume.Name, podName)
+			return nil
+		}
+	}
+	if status.ClaimName == "" {
+		status.Message = fmt.Sprintf("volume %s of pod %s not found", mount.Name, podName)
+		return nil
+	}
+	status.SubPath = cleanSubPath(path.Join(mount.SubPath, strings.TrimPrefix(status.MountPath, mount.MountPath)))
+
+	claim := &corev1.PersistentVolumeClaim{}
+	err = r.Get(ctx, types.NamespacedName{Name: status.ClaimName, Namespace: namespace}, claim)
+	if errors.IsNotFound(err) {
+		status.Message = "PersistentVolumeClaim not found"
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get PersistentVolumeClaim %s: %w", status.ClaimName, err)
+	}
+	status.ReadWriteOnce = readWriteOnce(claim)
+	if status.ReadWriteOnce {
+		status.MountedBy = stablePodLabels(pod)
+	}
+	return nil
+}
+
+// rdbMountConflict explains why an RDB volume cannot be mounted at
+// mountPath next to the other volumes of backup Jobs, or returns ""
+func rdbMountConflict(mountPath string, volumes []backupv1.RdbVolumeStatus) string {
+	for _, reserved := range reservedMountPaths {
+		if pathWithin(mountPath, reserved) || pathWithin(reserved, mountPath) {
+			return fmt.Sprintf("the directory of rdb_path, %s, overlaps %s used by backup Jobs", mountPath, reserved)
+		}
+	}
+	for _, volume := range volumes {
+		if volume.Message != "" {
+			continue
+		}
+		if pathWithin(mountPath, volume.MountPath) || pathWithin(volume.MountPath, mountPath) {
+			return fmt.Sprintf("the directory of rdb_path, %s, overlaps that of database %s", mountPath, volume.Database)
+		}
+	}
+	return ""
+}
+
+// cleanSubPath returns p relative to the root of a volume, without ".."
+func cleanSubPath(p string) string {
+	return strings.TrimPrefix(path.Clean("/"+p), "/")
+}
+
+// pathWithin reports whether p is dir or below it
+func pathWithin(p, dir string) bool {
+	dir = path.Clean(dir)
+	return p == dir || dir == "/" || strings.HasPrefix(p, dir+"/")
+}
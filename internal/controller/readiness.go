@@ -0,0 +1,113 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+)
+
+const (
+	// ConditionReady reports whether a Database or Storage can be used by
+	// Backups: its Secrets resolve and its configuration is valid
+	ConditionReady = "Ready"
+
+	// ConditionSecretsResolved reports whether every Secret key referenced
+	// by the config of a Database or Storage exists
+	ConditionSecretsResolved = "SecretsResolved"
+
+	// ReadinessCheckInterval is how often Databases and Storages are checked
+	// again, since the Services and objects they resolve are not watched
+	ReadinessCheckInterval = 5 * time.Minute
+)
+
+// setReadiness sets the Ready and SecretsResolved conditions from the errors
+// of resolving the Secrets of a config and of checking the rest of it
+func setReadiness(conditions *[]metav1.Condition, generation int64, secretsErr, configErr error) {
+	secrets := metav1.Condition{
+		Type:               ConditionSecretsResolved,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Resolved",
+		Message:            "Referenced Secrets resolved",
+		ObservedGeneration: generation,
+	}
+	ready := metav1.Condition{
+		Type:               ConditionReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Ready",
+		Message:            "Configuration is valid",
+		ObservedGeneration: generation,
+	}
+	switch {
+	case secretsErr != nil:
+		secrets.Status, secrets.Reason = metav1.ConditionFalse, "SecretUnresolved"
+		secrets.Message = truncateString(secretsErr.Error(), MaxMessageSize)
+		ready.Status, ready.Reason, ready.Message = metav1.ConditionFalse, "SecretsUnresolved", secrets.Message
+	case configErr != nil:
+		ready.Status, ready.Reason = metav1.ConditionFalse, "InvalidConfig"
+		ready.Message = truncateString(configErr.Error(), MaxMessageSize)
+	}
+	meta.SetStatusCondition(conditions, secrets)
+	meta.SetStatusCondition(conditions, ready)
+}
+
+// referencingBackups returns the sorted names of the Backups of a namespace
+// for which references is true
+func referencingBackups(ctx context.Context, c client.Client, namespace string, references func(*backupv1.Backup) bool) ([]string, error) {
+	backups := &backupv1.BackupList{}
+	if err := c.List(ctx, backups, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+	var names []string
+	for i := range backups.Items {
+		if references(&backups.Items[i]) {
+			names = append(names, backups.Items[i].Name)
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// configSecretNames returns the names of the Secrets referenced by the fields
+// ending with "_ref" of a typed Database or Storage config
+func configSecretNames(config interface{}) []string {
+	configMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(config)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for key, value := range configMap {
+		ref, ok := value.(map[string]interface{})
+		if !strings.HasSuffix(key, "_ref") || !ok {
+			continue
+		}
+		if name, ok := ref["name"].(string); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
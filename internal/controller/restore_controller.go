@@ -0,0 +1,414 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+	"github.com/gobackup/gobackup-operator/pkg/k8sutil"
+	"github.com/gobackup/gobackup-operator/pkg/storage"
+)
+
+// restoredArtifactPattern matches the line the download container prints
+// before it downloads the archive to restore
+var restoredArtifactPattern = regexp.MustCompile(`Restoring artifact: \S+`)
+
+// RestoreReconciler reconciles a Restore object
+type RestoreReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	K8s       *k8sutil.K8s
+	Clientset *kubernetes.Clientset
+}
+
+// +kubebuilder:rbac:groups=gobackup.io,resources=restores,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gobackup.io,resources=restores/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=gobackup.io,resources=restores/finalizers,verbs=update
+// +kubebuilder:rbac:groups=gobackup.io,resources=backupartifacts,verbs=get;list;watch
+
+// Reconcile starts the Job for a Restore and mirrors the Job's progress into
+// the Restore status. A Restore runs once; create a new one to restore again.
+func (r *RestoreReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	logger.Info("Reconciling Restore", "namespace", req.Namespace, "name", req.Name)
+
+	restore := &backupv1.Restore{}
+	if err := r.Get(ctx, req.NamespacedName, restore); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if err := refreshJobDefaults(ctx, r.Client); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if !restore.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	if restore.Status.Phase == "Succeeded" || restore.Status.Phase == "Failed" {
+		return ctrl.Result{}, nil
+	}
+
+	job := &batchv1.Job{}
+	err := r.Get(ctx, types.NamespacedName{Name: restore.Name, Namespace: restore.Namespace}, job)
+	if err != nil && !errors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+
+	if errors.IsNotFound(err) {
+		if restore.Status.JobName != "" {
+			return ctrl.Result{}, r.failRestore(ctx, restore, fmt.Sprintf("Job %s was deleted before the restore finished", restore.Status.JobName))
+		}
+		if err := r.startRestore(ctx, restore); err != nil {
+			logger.Error(err, "Failed to start restore")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	if !metav1.IsControlledBy(job, restore) {
+		return ctrl.Result{}, r.failRestore(ctx, restore, fmt.Sprintf("Job %s exists and is not owned by this Restore", job.Name))
+	}
+
+	phase := getJobPhase(job)
+	if restore.Status.Phase == phase {
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	r.updateRestoreStatus(ctx, restore, job)
+	if err := r.Status().Update(ctx, restore); err != nil {
+		if errors.IsConflict(err) {
+			return ctrl.Result{Requeue: true}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("failed to update restore status: %w", err)
+	}
+	logger.Info("Updated Restore status", "job", job.Name, "phase", restore.Status.Phase)
+
+	if restore.Status.Phase == "Running" || restore.Status.Phase == "Pending" {
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// startRestore resolves the archive to restore, renders the restore
+// environment and creates the Job, named after and owned by the Restore. Specs
+// that can never succeed fail the Restore.
+func (r *RestoreReconciler) startRestore(ctx context.Context, restore *backupv1.Restore) error {
+	key, err := r.resolveArtifactKey(ctx, restore)
+	if err != nil {
+		return err
+	}
+	if key == "" {
+		return r.failRestore(ctx, restore, fmt.Sprintf("No archive matching spec.artifact found in the BackupArtifacts of Storage %s; "+
+			"set artifact.key for storages the operator does not inventory", restore.Spec.StorageRef.Name))
+	}
+
+	dbType, err := r.K8s.CreateRestoreSecret(ctx, restore)
+	if err != nil {
+		return fmt.Errorf("failed to create secret for restore: %w", err)
+	}
+
+	switch dbType {
+	case "postgresql", "mysql", "mariadb", "mongodb":
+	case "redis", "etcd":
+		if restore.Spec.TargetVolume == nil {
+			return r.failRestore(ctx, restore, fmt.Sprintf("Restoring %s requires spec.targetVolume", dbType))
+		}
+	default:
+		return r.failRestore(ctx, restore, fmt.Sprintf("Restoring %s databases is not supported", dbType))
+	}
+
+	job := buildRestoreJob(restore, dbType, key)
+	if err := controllerutil.SetControllerReference(restore, job, r.Scheme); err != nil {
+		return fmt.Errorf("failed to set controller reference for restore Job: %w", err)
+	}
+	if err := r.Create(ctx, job); err != nil {
+		return fmt.Errorf("failed to create restore Job: %w", err)
+	}
+
+	now := metav1.Now()
+	restore.Status.JobName = job.Name
+	restore.Status.Phase = "Pending"
+	restore.Status.Artifact = key
+	restore.Status.StartTime = &now
+	if err := r.Status().Update(ctx, restore); err != nil {
+		return fmt.Errorf("failed to update restore status: %w", err)
+	}
+	return nil
+}
+
+// resolveArtifactKey returns the key of the archive a Restore selects. For
+// latest and timestamp, the newest matching archive is looked up in the
+// BackupArtifacts of the Storage, restricted to spec.backupRef and spec.tier;
+// "" means no archive matches.
+func (r *RestoreReconciler) resolveArtifactKey(ctx context.Context, restore *backupv1.Restore) (string, error) {
+	selector := restore.Spec.Artifact
+	if selector.Key != "" {
+		return selector.Key, nil
+	}
+
+	labels := client.MatchingLabels{StorageLabel: restore.Spec.StorageRef.Name}
+	if restore.Spec.BackupRef != nil {
+		labels[BackupLabel] = restore.Spec.BackupRef.Name
+	}
+	artifacts := &backupv1.BackupArtifactList{}
+	if err := r.List(ctx, artifacts, client.InNamespace(restore.Namespace), labels); err != nil {
+		return "", fmt.Errorf("failed to list backup artifacts: %w", err)
+	}
+
+	var key string
+	var newest time.Time
+	for _, artifact := range artifacts.Items {
+		if !isArtifactKey(artifact.Spec.Key) {
+			continue
+		}
+		if restore.Spec.Tier != "" && path.Dir(artifact.Spec.Key) != restore.Spec.Tier {
+			continue
+		}
+		object := storage.Object{Key: artifact.Spec.Key}
+		if artifact.Spec.Timestamp != nil {
+			object.LastModified = artifact.Spec.Timestamp.Time
+		}
+		created := archiveTime(object)
+		if selector.Timestamp != nil && created.After(selector.Timestamp.Time) {
+			continue
+		}
+		if key == "" || created.After(newest) || (created.Equal(newest) && artifact.Spec.Key > key) {
+			key, newest = artifact.Spec.Key, created
+		}
+	}
+	return key, nil
+}
+
+// updateRestoreStatus copies the Job's progress into the Restore status. The
+// logs are recorded once the Job has finished.
+func (r *RestoreReconciler) updateRestoreStatus(ctx context.Context, restore *backupv1.Restore, job *batchv1.Job) {
+	logger := log.FromContext(ctx)
+
+	status := &restore.Status
+	status.JobName = job.Name
+	status.Phase = getJobPhase(job)
+	if job.Status.StartTime != nil {
+		status.StartTime = job.Status.StartTime
+	}
+	status.CompletionTime = job.Status.CompletionTime
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobComplete && condition.Status == corev1.ConditionTrue {
+			status.Message = "Restore completed successfully"
+		} else if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			status.Message = truncateString(fmt.Sprintf("Restore failed: %s", condition.Message), MaxMessageSize)
+		}
+	}
+
+	if status.Phase != "Succeeded" && status.Phase != "Failed" {
+		return
+	}
+	if status.CompletionTime == nil {
+		now := metav1.Now()
+		status.CompletionTime = &now
+	}
+
+	downloaded := false
+	downloadLogs, err := collectPodLogs(ctx, r.Client, r.Clientset, job, "download")
+	if err != nil {
+		logger.V(1).Info("Failed to collect download logs", "error", err)
+	} else {
+		downloaded = restoredArtifactPattern.MatchString(downloadLogs)
+	}
+
+	// The restore container never started when the download failed
+	container := "restore"
+	if status.Phase == "Failed" && !downloaded {
+		container = "download"
+	}
+	logs, err := collectPodLogs(ctx, r.Client, r.Clientset, job, container)
+	if err != nil {
+		logger.V(1).Info("Failed to collect pod logs", "error", err)
+		status.Logs = truncateString(fmt.Sprintf("Failed to collect logs: %v", err), MaxLogSize)
+		return
+	}
+	status.Logs = logs
+}
+
+// failRestore marks a Restore as Failed with the given message
+func (r *RestoreReconciler) failRestore(ctx context.Context, restore *backupv1.Restore, message string) error {
+	now := metav1.Now()
+	restore.Status.Phase = "Failed"
+	restore.Status.Message = truncateString(message, MaxMessageSize)
+	restore.Status.CompletionTime = &now
+	if err := r.Status().Update(ctx, restore); err != nil {
+		return fmt.Errorf("failed to update restore status: %w", err)
+	}
+	return nil
+}
+
+// buildRestoreJob creates the Job for a Restore. An rclone init container
+// downloads the archive into a shared emptyDir, then the gobackup image, which
+// ships the database clients, unpacks it and loads the dump.
+func buildRestoreJob(restore *backupv1.Restore, dbType, key string) *batchv1.Job {
+	defaults := jobDefaults()
+	downloadImage := os.Getenv("RESTORE_DOWNLOAD_IMAGE")
+	if downloadImage == "" {
+		downloadImage = "rclone/rclone:1.68"
+	}
+
+	envFrom := []corev1.EnvFromSource{
+		{
+			SecretRef: &corev1.SecretEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: k8sutil.RestoreSecretName(restore)},
+			},
+		},
+	}
+
+	downloadEnv := []corev1.EnvVar{{Name: "ARTIFACT_KEY", Value: key}}
+
+	sourceDatabase := restore.Spec.SourceDatabase
+	if sourceDatabase == "" {
+		sourceDatabase = restore.Spec.DatabaseRef.Name
+	}
+	restoreEnv := []corev1.EnvVar{
+		{Name: "DB_TYPE", Value: dbType},
+		{Name: "SOURCE_DATABASE", Value: sourceDatabase},
+		{Name: "TAR_FLAGS", Value: restoreTarFlags(restore.Spec.CompressWith)},
+	}
+	if encode := restore.Spec.EncodeWith; encode != nil {
+		flags := []string{}
+		if encode.Base64 {
+			flags = append(flags, "-base64")
+		}
+		if encode.Args != "" {
+			flags = append(flags, encode.Args)
+		}
+		restoreEnv = append(restoreEnv,
+			corev1.EnvVar{Name: "DECRYPT_CIPHER", Value: encode.Cipher},
+			corev1.EnvVar{Name: "DECRYPT_FLAGS", Value: strings.Join(flags, " ")},
+		)
+	}
+
+	volumes := []corev1.Volume{
+		{
+			Name:         "restore",
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		},
+	}
+	restoreMounts := []corev1.VolumeMount{{Name: "restore", MountPath: "/restore"}}
+	if target := restore.Spec.TargetVolume; target != nil {
+		volumes = append(volumes, corev1.Volume{
+			Name: "target",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: target.ClaimName},
+			},
+		})
+		restoreMounts = append(restoreMounts, corev1.VolumeMount{Name: "target", MountPath: "/target"})
+		restoreEnv = append(restoreEnv, corev1.EnvVar{Name: "TARGET_PATH", Value: target.Path})
+	}
+
+	// A failed restore may have partially loaded the dump; never retry it blindly
+	backoffLimit := int32(0)
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      restore.Name,
+			Namespace: restore.Namespace,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            &backoffLimit,
+			TTLSecondsAfterFinished: defaults.TTLSecondsAfterFinished,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					InitContainers: []corev1.Container{
+						{
+							Name:            "download",
+							Image:           downloadImage,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Command:         []string{"/bin/sh", "-c", downloadScript},
+							EnvFrom:         envFrom,
+							Env:             downloadEnv,
+							VolumeMounts:    []corev1.VolumeMount{{Name: "restore", MountPath: "/restore"}},
+							SecurityContext: defaults.SecurityContext,
+						},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:            "restore",
+							Image:           defaults.Image,
+							ImagePullPolicy: defaults.ImagePullPolicy,
+							Command:         []string{"/bin/sh", "-c", restoreScript},
+							EnvFrom:         envFrom,
+							Env:             restoreEnv,
+							VolumeMounts:    restoreMounts,
+							SecurityContext: defaults.SecurityContext,
+						},
+					},
+					Volumes:          volumes,
+					RestartPolicy:    corev1.RestartPolicyNever,
+					ImagePullSecrets: defaults.ImagePullSecrets,
+					SecurityContext:  defaults.PodSecurityContext,
+				},
+			},
+		},
+	}
+	if defaults.Resources != nil {
+		job.Spec.Template.Spec.Containers[0].Resources = *defaults.Resources
+	}
+	return job
+}
+
+// restoreTarFlags returns the tar flag that decompresses an archive created
+// with the given compression
+func restoreTarFlags(compress *backupv1.Compress) string {
+	if compress == nil {
+		return "-z"
+	}
+	switch compress.Type {
+	case "tar":
+		return ""
+	case "tbz", "tar.bz2":
+		return "-j"
+	case "txz", "tar.xz":
+		return "-J"
+	default:
+		return "-z"
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RestoreReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&backupv1.Restore{}).
+		Owns(&batchv1.Job{}).
+		Complete(r)
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+)
+
+// testArtifact returns the BackupArtifact of an archive in Storage s3,
+// attributed to backup when it is set
+func testArtifact(key, backup string) *backupv1.BackupArtifact {
+	artifact := &backupv1.BackupArtifact{
+		ObjectMeta: metav1.ObjectMeta{Name: artifactObjectName("s3", key), Namespace: "default",
+			Labels: map[string]string{StorageLabel: "s3"}},
+		Spec: backupv1.BackupArtifactSpec{StorageRef: corev1.LocalObjectReference{Name: "s3"}, Key: key},
+	}
+	if backup != "" {
+		artifact.Labels[BackupLabel] = backup
+		artifact.Spec.BackupRef = &corev1.LocalObjectReference{Name: backup}
+	}
+	return artifact
+}
+
+func TestResolveArtifactKey(t *testing.T) {
+	c := newTestClient(t,
+		testArtifact("2024.06.14.02.00.00.tar.gz", "app"),
+		testArtifact("daily/2024.06.15.02.00.00.tar.gz", "app"),
+		testArtifact("weekly/2024.06.09.03.00.00.tar.gz", "app"),
+		testArtifact("2024.06.16.01.00.00.tar.gz", "other"),
+		testArtifact("2024.06.17.01.00.00.tar.gz", ""),
+		testArtifact("notes.txt", "app"),
+	)
+	r := &RestoreReconciler{Client: c, Scheme: c.Scheme()}
+	at := func(value string) *metav1.Time {
+		parsed, _ := time.Parse(time.RFC3339, value)
+		return &metav1.Time{Time: parsed}
+	}
+
+	tests := []struct {
+		name     string
+		backup   string
+		tier     string
+		artifact backupv1.RestoreArtifact
+		want     string
+	}{
+		{name: "key", artifact: backupv1.RestoreArtifact{Key: "weekly/2024.06.09.03.00.00.tar.gz"}, want: "weekly/2024.06.09.03.00.00.tar.gz"},
+		{name: "latest in the Storage", artifact: backupv1.RestoreArtifact{Latest: true}, want: "2024.06.17.01.00.00.tar.gz"},
+		{name: "latest of a Backup", backup: "app", artifact: backupv1.RestoreArtifact{Latest: true}, want: "daily/2024.06.15.02.00.00.tar.gz"},
+		{name: "latest of a tier", backup: "app", tier: "weekly", artifact: backupv1.RestoreArtifact{Latest: true}, want: "weekly/2024.06.09.03.00.00.tar.gz"},
+		{name: "timestamp", backup: "app", artifact: backupv1.RestoreArtifact{Timestamp: at("2024-06-15T01:59:59Z")}, want: "2024.06.14.02.00.00.tar.gz"},
+		{name: "timestamp matching an archive", backup: "app", artifact: backupv1.RestoreArtifact{Timestamp: at("2024-06-15T02:00:00Z")}, want: "daily/2024.06.15.02.00.00.tar.gz"},
+		{name: "timestamp before every archive", backup: "app", artifact: backupv1.RestoreArtifact{Timestamp: at("2024-01-01T00:00:00Z")}, want: ""},
+		{name: "unknown Backup", backup: "gone", artifact: backupv1.RestoreArtifact{Latest: true}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restore := &backupv1.Restore{
+				ObjectMeta: metav1.ObjectMeta{Name: "restore", Namespace: "default"},
+				Spec: backupv1.RestoreSpec{
+					StorageRef: corev1.LocalObjectReference{Name: "s3"},
+					Tier:       tt.tier,
+					Artifact:   tt.artifact,
+				},
+			}
+			if tt.backup != "" {
+				restore.Spec.BackupRef = &corev1.LocalObjectReference{Name: tt.backup}
+			}
+			got, err := r.resolveArtifactKey(context.Background(), restore)
+			if err != nil {
+				t.Fatalf("resolveArtifactKey failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("resolveArtifactKey = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartRestoreWithoutArchive(t *testing.T) {
+	restore := &backupv1.Restore{
+		ObjectMeta: metav1.ObjectMeta{Name: "restore", Namespace: "default"},
+		Spec: backupv1.RestoreSpec{
+			StorageRef: corev1.LocalObjectReference{Name: "s3"},
+			BackupRef:  &corev1.LocalObjectReference{Name: "app"},
+			Artifact:   backupv1.RestoreArtifact{Latest: true},
+		},
+	}
+	c := newTestClient(t, restore, testArtifact("2024.06.16.01.00.00.tar.gz", "other"))
+	r := &RestoreReconciler{Client: c, Scheme: c.Scheme()}
+
+	if err := r.startRestore(context.Background(), restore); err != nil {
+		t.Fatalf("startRestore failed: %v", err)
+	}
+	got := &backupv1.Restore{}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(restore), got); err != nil {
+		t.Fatalf("failed to get restore: %v", err)
+	}
+	if got.Status.Phase != "Failed" || got.Status.JobName != "" {
+		t.Errorf("status = %+v, want Failed without a Job", got.Status)
+	}
+}
+
+func TestBuildRestoreJobArtifactKey(t *testing.T) {
+	restore := &backupv1.Restore{
+		ObjectMeta: metav1.ObjectMeta{Name: "restore", Namespace: "default"},
+		Spec:       backupv1.RestoreSpec{Artifact: backupv1.RestoreArtifact{Latest: true}},
+	}
+	job := buildRestoreJob(restore, "postgresql", "daily/2024.06.15.02.00.00.tar.gz")
+	env := job.Spec.Template.Spec.InitContainers[0].Env
+	if len(env) != 1 || env[0].Name != "ARTIFACT_KEY" || env[0].Value != "daily/2024.06.15.02.00.00.tar.gz" {
+		t.Errorf("download env = %v, want ARTIFACT_KEY of the resolved archive", env)
+	}
+}
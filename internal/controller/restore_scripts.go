@@ -0,0 +1,98 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+// downloadScript runs in the rclone init container. It copies the archive
+// ARTIFACT_KEY, which the controller resolved, from SOURCE_PATH into /restore,
+// recording its name in /restore/.artifact. Passwords arrive in plain text as
+// <OPTION>_PLAIN and are obscured here, because rclone only accepts obscured
+// passwords.
+const downloadScript = `set -eu
+for option in PASS KEY_FILE_PASS; do
+  eval "plain=\${${option}_PLAIN:-}"
+  if [ -n "$plain" ]; then
+    export "RCLONE_CONFIG_SOURCE_${option}=$(rclone obscure "$plain")"
+  fi
+done
+
+key="$ARTIFACT_KEY"
+echo "Restoring artifact: $key"
+rclone copyto "${SOURCE_PATH}${key}" "/restore/$(basename "$key")"
+basename "$key" > /restore/.artifact
+`
+
+// restoreScript runs in the gobackup container. It decrypts and unpacks the
+// downloaded archive, finds the dump of SOURCE_DATABASE and loads it into the
+// target database, or into the target volume for file based engines.
+const restoreScript = `set -eu
+cd /restore
+archive=$(cat .artifact)
+
+if [ -n "${DECRYPT_PASSWORD:-}" ]; then
+  echo "Decrypting $archive"
+  openssl enc -d -"${DECRYPT_CIPHER:-aes-256-cbc}" ${DECRYPT_FLAGS:-} -k "$DECRYPT_PASSWORD" -in "$archive" -out archive.decrypted
+  archive=archive.decrypted
+fi
+
+mkdir -p extracted
+tar -x ${TAR_FLAGS:-} -f "$archive" -C extracted
+
+dir=$(find extracted -type d -ipath "*/${DB_TYPE}/${SOURCE_DATABASE}" | head -n 1)
+if [ -z "$dir" ]; then
+  echo "No ${DB_TYPE} dump named ${SOURCE_DATABASE} found in $archive" >&2
+  exit 1
+fi
+file=$(find "$dir" -type f | head -n 1)
+echo "Restoring ${DB_TYPE} dump from $dir"
+
+case "$DB_TYPE" in
+postgresql)
+  export PGPASSWORD="${DB_PASSWORD:-}"
+  set -- -h "${DB_HOST:-localhost}" -p "${DB_PORT:-5432}" -d "${DB_DATABASE}"
+  if [ -n "${DB_USERNAME:-}" ]; then set -- "$@" -U "$DB_USERNAME"; fi
+  case "$file" in
+  *.sql) psql "$@" -v ON_ERROR_STOP=1 -f "$file" ;;
+  *) pg_restore "$@" --clean --if-exists "$file" ;;
+  esac
+  ;;
+mysql|mariadb)
+  export MYSQL_PWD="${DB_PASSWORD:-}"
+  set -- -h "${DB_HOST:-localhost}" -P "${DB_PORT:-3306}"
+  if [ -n "${DB_USERNAME:-}" ]; then set -- "$@" -u "$DB_USERNAME"; fi
+  mysql "$@" "${DB_DATABASE}" < "$file"
+  ;;
+mongodb)
+  set -- --drop --host "${DB_HOST:-localhost}" --port "${DB_PORT:-27017}"
+  if [ -n "${DB_USERNAME:-}" ]; then set -- "$@" --username "$DB_USERNAME" --password "${DB_PASSWORD:-}"; fi
+  if [ -n "${DB_AUTH_DB:-}" ]; then set -- "$@" --authenticationDatabase "$DB_AUTH_DB"; fi
+  mongorestore "$@" "$dir"
+  ;;
+redis)
+  rdb=$(find "$dir" -type f -name '*.rdb' | head -n 1)
+  cp "${rdb:-$file}" "/target/${TARGET_PATH:-dump.rdb}"
+  ;;
+etcd)
+  etcdctl snapshot restore "$file" --data-dir "/target/${TARGET_PATH:-default.etcd}"
+  ;;
+*)
+  echo "Restoring ${DB_TYPE} is not supported" >&2
+  exit 1
+  ;;
+esac
+
+echo "Restore completed"
+`
@@ -0,0 +1,234 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+	"github.com/gobackup/gobackup-operator/pkg/retention"
+	"github.com/gobackup/gobackup-operator/pkg/storage"
+)
+
+// artifactTimeLayout is the layout of the timestamp gobackup puts in archive names
+const artifactTimeLayout = "2006.01.02.15.04.05"
+
+// reconcileRetention enforces the retention policy of every storage that has
+// one, once per successful run: a storage is pruned when the Backup succeeded
+// after its last prune. Failures are reported in status.retention and retried
+// on the next reconcile; they do not fail the reconcile.
+func (r *BackupReconciler) reconcileRetention(ctx context.Context, backup *backupv1.Backup) error {
+	logger := log.FromContext(ctx)
+
+	original := backup.DeepCopy()
+	lastSuccess := latestSuccessTime(backup)
+
+	var statuses []backupv1.StorageRetentionStatus
+	for _, ref := range backup.Spec.StorageRefs {
+		if ref.Retention == nil {
+			continue
+		}
+
+		status := backupv1.StorageRetentionStatus{Storage: ref.Name}
+		for _, existing := range backup.Status.Retention {
+			if existing.Storage == ref.Name {
+				status = existing
+			}
+		}
+
+		if lastSuccess != nil && (status.LastPruneTime == nil || status.LastPruneTime.Before(lastSuccess)) {
+			kept, deleted, err := r.pruneStorage(ctx, backup, ref)
+			if err != nil {
+				logger.Error(err, "Failed to enforce retention policy", "storage", ref.Name)
+				status.Message = truncateString(err.Error(), MaxMessageSize)
+			} else {
+				now := metav1.Now()
+				status = backupv1.StorageRetentionStatus{
+					Storage:       ref.Name,
+					LastPruneTime: &now,
+					Kept:          kept,
+					Deleted:       deleted,
+				}
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	backup.Status.Retention = statuses
+
+	if equality.Semantic.DeepEqual(original.Status, backup.Status) {
+		return nil
+	}
+	if err := r.Status().Patch(ctx, backup, client.MergeFrom(original)); err != nil {
+		return fmt.Errorf("failed to update retention status: %w", err)
+	}
+	return nil
+}
+
+// pruneStorage deletes the archives of the Backup that its retention policy
+// does not keep from a storage, and returns the keys kept and deleted. Only
+// archives attributed to the Backup are considered, so storages shared with
+// other Backups are safe.
+func (r *BackupReconciler) pruneStorage(ctx context.Context, backup *backupv1.Backup, ref backupv1.StorageRef) ([]string, []string, error) {
+	logger := log.FromContext(ctx)
+
+	store, err := r.openStorage(ctx, backup.Namespace, ref, tierDirs(backup, ref.Name))
+	if err != nil {
+		return nil, nil, err
+	}
+	objects, err := store.List(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list storage %s: %w", ref.Name, err)
+	}
+
+	owners, err := artifactOwners(ctx, r.Client, backup.Namespace, ref.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The inventory remembers owners whose BackupRuns were pruned since
+	artifacts := &backupv1.BackupArtifactList{}
+	if err := r.List(ctx, artifacts, client.InNamespace(backup.Namespace),
+		client.MatchingLabels{BackupLabel: backup.Name, StorageLabel: ref.Name}); err != nil {
+		return nil, nil, fmt.Errorf("failed to list backup artifacts: %w", err)
+	}
+	for _, artifact := range artifacts.Items {
+		if _, ok := owners.byFilename[artifact.Spec.Key]; !ok {
+			owners.byFilename[artifact.Spec.Key] = backup.Name
+		}
+	}
+
+	var archives []retention.Archive
+	for _, object := range objects {
+		if !isArtifactKey(object.Key) || owners.backupFor(object.Key) != backup.Name {
+			continue
+		}
+		archives = append(archives, retention.Archive{Key: object.Key, Time: archiveTime(object)})
+	}
+
+	keep, drop := retention.Apply(retentionPolicy(ref.Retention), archives, time.Now())
+
+	var deleted []string
+	for _, archive := range drop {
+		if err := store.Delete(ctx, archive.Key); err != nil {
+			return nil, nil, fmt.Errorf("failed to delete artifact %s from storage %s: %w", archive.Key, ref.Name, err)
+		}
+		logger.Info("Deleted artifact by retention policy", "storage", ref.Name, "key", archive.Key)
+		deleted = append(deleted, archive.Key)
+
+		artifact := &backupv1.BackupArtifact{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      artifactObjectName(ref.Name, archive.Key),
+				Namespace: backup.Namespace,
+			},
+		}
+		if err := r.Delete(ctx, artifact); client.IgnoreNotFound(err) != nil {
+			return nil, nil, fmt.Errorf("failed to delete backup artifact %s: %w", artifact.Name, err)
+		}
+	}
+
+	var kept []string
+	for _, archive := range keep {
+		kept = append(kept, archive.Key)
+	}
+	return kept, deleted, nil
+}
+
+// storageConfigError is returned by openStorage when storage.New rejects a
+// Storage: its type cannot be listed or its config is incomplete
+type storageConfigError struct {
+	name string
+	err  error
+}
+
+func (e *storageConfigError) Error() string {
+	return fmt.Sprintf("cannot access storage %s: %v", e.name, e.err)
+}
+
+func (e *storageConfigError) Unwrap() error {
+	return e.err
+}
+
+// openStorage returns a client for a Storage referenced by a Backup, covering
+// the given directories of schedule tiers under its path. A missing Storage
+// or Secret is reported as a NotFound error, a Storage storage.New rejects as
+// a *storageConfigError.
+func (r *BackupReconciler) openStorage(ctx context.Context, namespace string, ref backupv1.StorageRef, dirs []string) (storage.Client, error) {
+	storageType, config, err := r.K8s.ResolveStorage(ctx, namespace, ref.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve storage %s: %w", ref.Name, err)
+	}
+	if storageType == "" {
+		storageType = strings.ToLower(ref.Type)
+	}
+	store, err := storage.NewWithDirs(storageType, config, dirs)
+	if err != nil {
+		return nil, &storageConfigError{name: ref.Name, err: err}
+	}
+	return store, nil
+}
+
+// retentionPolicy converts the API retention policy
+func retentionPolicy(policy *backupv1.RetentionPolicy) retention.Policy {
+	count := func(value *int32) int {
+		if value == nil {
+			return 0
+		}
+		return int(*value)
+	}
+	result := retention.Policy{
+		KeepLast:    count(policy.KeepLast),
+		KeepDaily:   count(policy.KeepDaily),
+		KeepWeekly:  count(policy.KeepWeekly),
+		KeepMonthly: count(policy.KeepMonthly),
+		KeepYearly:  count(policy.KeepYearly),
+	}
+	if policy.MaxAge != nil {
+		result.MaxAge = policy.MaxAge.Duration
+	}
+	return result
+}
+
+// archiveTime returns when an archive was created, taken from the timestamp
+// gobackup puts in its name, or the object's modification time
+func archiveTime(object storage.Object) time.Time {
+	name := artifactFilenamePattern.FindString(object.Key)
+	if len(name) >= len(artifactTimeLayout) {
+		if t, err := time.Parse(artifactTimeLayout, name[:len(artifactTimeLayout)]); err == nil {
+			return t
+		}
+	}
+	return object.LastModified
+}
+
+// latestSuccessTime returns when the Backup, or any of its schedule tiers,
+// last succeeded
+func latestSuccessTime(backup *backupv1.Backup) *metav1.Time {
+	latest := backup.Status.LastSuccessfulBackupTime
+	for _, tier := range backup.Status.Tiers {
+		if t := tier.LastSuccessfulBackupTime; t != nil && (latest == nil || latest.Before(t)) {
+			latest = t
+		}
+	}
+	return latest
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+)
+
+const (
+	// ConditionStale reports whether the last successful run of a Backup is
+	// older than its spec.rpo
+	ConditionStale = "Stale"
+)
+
+var (
+	backupStale = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "gobackup_backup_stale",
+		Help: "1 when the last successful run of a Backup is older than its spec.rpo, 0 otherwise",
+	}, []string{"namespace", "backup"})
+
+	backupLastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "gobackup_backup_last_success_timestamp_seconds",
+		Help: "Unix time of the last successful run of a Backup with spec.rpo",
+	}, []string{"namespace", "backup"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(backupStale, backupLastSuccess)
+}
+
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
+
+// reconcileRPO checks the last successful run of the Backup against its
+// spec.rpo, and returns how long until the Backup turns stale. A Backup that
+// never succeeded is measured from its creation, so a CronJob that silently
+// stops creating Jobs is caught as well as one whose runs fail.
+func (r *BackupReconciler) reconcileRPO(ctx context.Context, backup *backupv1.Backup) (time.Duration, error) {
+	original := backup.DeepCopy()
+	status := &backup.Status
+
+	if backup.Spec.RPO == nil {
+		meta.RemoveStatusCondition(&status.Conditions, ConditionStale)
+		forgetRPOMetrics(backup)
+		return 0, r.patchRPOStatus(ctx, original, backup)
+	}
+
+	since := backup.CreationTimestamp
+	if latest := latestSuccessTime(backup); latest != nil {
+		since = *latest
+		backupLastSuccess.WithLabelValues(backup.Namespace, backup.Name).Set(float64(latest.Unix()))
+	}
+	age := time.Since(since.Time)
+	rpo := backup.Spec.RPO.Duration
+
+	if age <= rpo {
+		backupStale.WithLabelValues(backup.Namespace, backup.Name).Set(0)
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               ConditionStale,
+			Status:             metav1.ConditionFalse,
+			Reason:             "WithinRPO",
+			Message:            fmt.Sprintf("Last successful run is within the RPO of %s", rpo),
+			ObservedGeneration: backup.Generation,
+		})
+		return rpo - age + time.Second, r.patchRPOStatus(ctx, original, backup)
+	}
+
+	backupStale.WithLabelValues(backup.Namespace, backup.Name).Set(1)
+	// The message must not change between reconciles, or every status patch
+	// would trigger the next reconcile
+	message := fmt.Sprintf("Last successful run at %s is older than the RPO of %s", since.UTC().Format(time.RFC3339), rpo)
+	if latestSuccessTime(backup) == nil {
+		message = fmt.Sprintf("No successful run since the Backup was created at %s, exceeding the RPO of %s", since.UTC().Format(time.RFC3339), rpo)
+	}
+	if !meta.IsStatusConditionTrue(status.Conditions, ConditionStale) {
+		log.FromContext(ctx).Info("Backup exceeded its RPO", "rpo", rpo, "age", age)
+		r.Recorder.Eventf(backup, nil, corev1.EventTypeWarning, "RPOExceeded", "CheckRPO", "%s", message)
+	}
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               ConditionStale,
+		Status:             metav1.ConditionTrue,
+		Reason:             "RPOExceeded",
+		Message:            message,
+		ObservedGeneration: backup.Generation,
+	})
+	// A successful run triggers a reconcile through its Job, so stale
+	// Backups need no timer
+	return 0, r.patchRPOStatus(ctx, original, backup)
+}
+
+// patchRPOStatus writes the Stale condition, unless it is unchanged
+func (r *BackupReconciler) patchRPOStatus(ctx context.Context, original, backup *backupv1.Backup) error {
+	if equality.Semantic.DeepEqual(original.Status, backup.Status) {
+		return nil
+	}
+	if err := r.Status().Patch(ctx, backup, client.MergeFrom(original)); err != nil {
+		return fmt.Errorf("failed to update stale condition: %w", err)
+	}
+	return nil
+}
+
+// forgetRPOMetrics drops the metric series of a Backup
+func forgetRPOMetrics(backup *backupv1.Backup) {
+	backupStale.DeleteLabelValues(backup.Namespace, backup.Name)
+	backupLastSuccess.DeleteLabelValues(backup.Namespace, backup.Name)
+}
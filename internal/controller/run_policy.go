@@ -0,0 +1,186 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+)
+
+// Reasons a run failed or is stuck, reported in BackupRunStatus.Reason
+const (
+	ReasonOOMKilled        = "OOMKilled"
+	ReasonDeadlineExceeded = "DeadlineExceeded"
+	ReasonImagePullBackOff = "ImagePullBackOff"
+	ReasonNonZeroExit      = "NonZeroExit"
+	ReasonPodFailurePolicy = "PodFailurePolicy"
+	ReasonUnknown          = "Unknown"
+)
+
+// defaultBackoffLimit is the Kubernetes default for JobSpec.BackoffLimit
+const defaultBackoffLimit = 6
+
+// imagePullReasons are the container waiting reasons of an image that cannot be pulled
+var imagePullReasons = map[string]bool{
+	"ErrImagePull":      true,
+	"ImagePullBackOff":  true,
+	"InvalidImageName":  true,
+	"ErrImageNeverPull": true,
+}
+
+// applyRunPolicy maps spec.runPolicy onto a backup JobSpec. With a retry
+// delay, every attempt is a Job of its own, so Kubernetes must not retry.
+func applyRunPolicy(spec *batchv1.JobSpec, policy *backupv1.RunPolicy) {
+	if policy == nil {
+		return
+	}
+	spec.BackoffLimit = policy.BackoffLimit
+	if policy.TTLSecondsAfterFinished != nil {
+		spec.TTLSecondsAfterFinished = policy.TTLSecondsAfterFinished
+	}
+	if policy.RetryDelay != nil {
+		noRetries := int32(0)
+		spec.BackoffLimit = &noRetries
+	}
+	spec.ActiveDeadlineSeconds = policy.ActiveDeadlineSeconds
+	spec.PodFailurePolicy = policy.PodFailurePolicy.DeepCopy()
+}
+
+// classifyRun sets the reason, and the exit code of failed runs, from the
+// Job's conditions and the state of its pods. Runs that have not failed only
+// get a reason when their image cannot be pulled.
+func classifyRun(ctx context.Context, c client.Reader, job *batchv1.Job, status *backupv1.BackupRunStatus) {
+	pods := &corev1.PodList{}
+	if err := c.List(ctx, pods, client.InNamespace(job.Namespace), client.MatchingLabels{"job-name": job.Name}); err != nil {
+		pods.Items = nil
+	}
+	// Newest pod first: it holds the last attempt
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[j].CreationTimestamp.Before(&pods.Items[i].CreationTimestamp)
+	})
+
+	var pullMessage string
+	var terminated *corev1.ContainerStateTerminated
+	for i := range pods.Items {
+		for _, container := range pods.Items[i].Status.ContainerStatuses {
+			if waiting := container.State.Waiting; waiting != nil && imagePullReasons[waiting.Reason] && pullMessage == "" {
+				pullMessage = waiting.Message
+				if pullMessage == "" {
+					pullMessage = waiting.Reason
+				}
+			}
+			if state := container.State.Terminated; state != nil && container.Name == "gobackup" && terminated == nil {
+				terminated = state
+			}
+		}
+	}
+
+	if status.Phase != "Failed" {
+		if pullMessage != "" {
+			status.Reason = ReasonImagePullBackOff
+			status.Message = truncateString("Cannot pull image: "+pullMessage, MaxMessageSize)
+		}
+		return
+	}
+
+	var failed *batchv1.JobCondition
+	for i := range job.Status.Conditions {
+		if job.Status.Conditions[i].Type == batchv1.JobFailed {
+			failed = &job.Status.Conditions[i]
+		}
+	}
+
+	switch {
+	case pullMessage != "":
+		status.Reason = ReasonImagePullBackOff
+	case failed != nil && failed.Reason == batchv1.JobReasonDeadlineExceeded:
+		status.Reason = ReasonDeadlineExceeded
+	case terminated != nil && terminated.Reason == "OOMKilled":
+		status.Reason = ReasonOOMKilled
+	case terminated != nil && terminated.ExitCode != 0:
+		status.Reason = ReasonNonZeroExit
+	case failed != nil && failed.Reason == batchv1.JobReasonPodFailurePolicy:
+		status.Reason = ReasonPodFailurePolicy
+	default:
+		status.Reason = ReasonUnknown
+	}
+	if terminated != nil && terminated.ExitCode != 0 {
+		exitCode := terminated.ExitCode
+		status.ExitCode = &exitCode
+	}
+}
+
+// canRetry reports whether the operator retries a run whose Job failed
+func canRetry(backup *backupv1.Backup, run *backupv1.BackupRun, job *batchv1.Job) bool {
+	policy := backup.Spec.RunPolicy
+	if policy == nil || policy.RetryDelay == nil {
+		return false
+	}
+
+	// A FailJob rule of the pod failure policy means retrying is pointless
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Reason == batchv1.JobReasonPodFailurePolicy {
+			return false
+		}
+	}
+
+	limit := int32(defaultBackoffLimit)
+	if policy.BackoffLimit != nil {
+		limit = *policy.BackoffLimit
+	}
+	return runAttempts(run) <= limit
+}
+
+// jobFailedAt returns when a Job failed, or now if it has no Failed condition
+func jobFailedAt(job *batchv1.Job) metav1.Time {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return condition.LastTransitionTime
+		}
+	}
+	return metav1.Now()
+}
+
+// retryAfter returns how long a Retrying run still waits for its next attempt
+func retryAfter(backup *backupv1.Backup, run *backupv1.BackupRun) time.Duration {
+	if backup.Spec.RunPolicy == nil || backup.Spec.RunPolicy.RetryDelay == nil || run.Status.CompletionTime == nil {
+		return 0
+	}
+	return time.Until(run.Status.CompletionTime.Add(backup.Spec.RunPolicy.RetryDelay.Duration))
+}
+
+// runAttempts returns the number of Jobs started for a run so far
+func runAttempts(run *backupv1.BackupRun) int32 {
+	if run.Status.Attempts == 0 {
+		return 1
+	}
+	return run.Status.Attempts
+}
+
+// retryJobName names the Job of the given attempt of a run
+func retryJobName(run *backupv1.BackupRun, attempt int32) string {
+	return fmt.Sprintf("%s-retry-%d", run.Name, attempt)
+}
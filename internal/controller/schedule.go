@@ -0,0 +1,305 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+)
+
+const (
+	// ConditionScheduleValid reports whether spec.schedule, or every entry of
+	// spec.schedules, can be turned into a CronJob
+	ConditionScheduleValid = "ScheduleValid"
+)
+
+// cronParser accepts the same expressions as the Kubernetes CronJob controller:
+// five fields or a descriptor such as @daily
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// parseSchedule parses a cron expression evaluated in the given IANA time zone.
+// An empty time zone means UTC, the usual time zone of kube-controller-manager.
+func parseSchedule(expression string, timeZone string) (cron.Schedule, error) {
+	expression = strings.TrimSpace(expression)
+	if expression == "" {
+		return nil, fmt.Errorf("cron expression cannot be empty")
+	}
+	// The CronJob API rejects both, so catch them here with a clear message
+	if strings.HasPrefix(expression, "TZ=") || strings.HasPrefix(expression, "CRON_TZ=") {
+		return nil, fmt.Errorf("invalid cron expression %q: set spec.schedule.timeZone instead of a TZ prefix", expression)
+	}
+	if strings.HasPrefix(expression, "@every") {
+		return nil, fmt.Errorf("invalid cron expression %q: @every is not supported by CronJobs", expression)
+	}
+
+	location := time.UTC
+	if timeZone != "" {
+		loc, err := time.LoadLocation(timeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", timeZone, err)
+		}
+		location = loc
+	}
+
+	schedule, err := cronParser.Parse(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %w", expression, err)
+	}
+	if spec, ok := schedule.(*cron.SpecSchedule); ok {
+		spec.Location = location
+	}
+	// Next returns the zero time for expressions that never fire, such as
+	// 0 0 30 2 *, which the CronJob API accepts
+	if schedule.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("invalid cron expression %q: it never fires", expression)
+	}
+	return schedule, nil
+}
+
+// hashedFields are the bounds H resolves within, per cron field. Days of the
+// month stop at 28 so a hashed day exists in every month.
+var hashedFields = [5]struct {
+	name     string
+	min, max int
+}{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 28},
+	{"month", 1, 12},
+	{"day of week", 0, 6},
+}
+
+// hashedToken matches H, H(a-b), H/n and H(a-b)/n
+var hashedToken = regexp.MustCompile(`^H(?:\((\d+)-(\d+)\))?(?:/(\d+))?$`)
+
+// resolveHashedCron replaces the Jenkins-style H tokens of a five field cron
+// expression with values derived from seed, so Backups sharing an expression
+// such as "H 2 * * *" start at different, but stable, times. Expressions
+// without H, including macros, are returned unchanged.
+func resolveHashedCron(expression, seed string) (string, error) {
+	expression = strings.TrimSpace(expression)
+	fields := strings.Fields(expression)
+	hashed := false
+	for _, field := range fields {
+		for _, item := range strings.Split(field, ",") {
+			// Only items starting with H are hashed; THU is a day name
+			hashed = hashed || strings.HasPrefix(item, "H")
+		}
+	}
+	if !hashed {
+		return expression, nil
+	}
+	if len(fields) != len(hashedFields) {
+		return "", fmt.Errorf("invalid cron expression %q: H requires five fields", expression)
+	}
+
+	for i, field := range fields {
+		bounds := hashedFields[i]
+		items := strings.Split(field, ",")
+		for j, item := range items {
+			if !strings.HasPrefix(item, "H") {
+				continue
+			}
+			match := hashedToken.FindStringSubmatch(item)
+			if match == nil {
+				return "", fmt.Errorf("invalid cron expression %q: unsupported %s %q", expression, bounds.name, item)
+			}
+
+			low, high := bounds.min, bounds.max
+			if match[1] != "" {
+				low, _ = strconv.Atoi(match[1])
+				high, _ = strconv.Atoi(match[2])
+				if low < bounds.min || high > bounds.max || low > high {
+					return "", fmt.Errorf("invalid cron expression %q: %s range %s-%s out of bounds %d-%d",
+						expression, bounds.name, match[1], match[2], bounds.min, bounds.max)
+				}
+			}
+
+			hash := fnv.New32a()
+			_, _ = hash.Write([]byte(fmt.Sprintf("%s/%d", seed, i)))
+			sum := int(hash.Sum32() & 0x7fffffff)
+
+			if match[3] == "" {
+				items[j] = strconv.Itoa(low + sum%(high-low+1))
+				continue
+			}
+			step, _ := strconv.Atoi(match[3])
+			if step == 0 {
+				return "", fmt.Errorf("invalid cron expression %q: step of %q must be positive", expression, item)
+			}
+			start := low + sum%min(step, high-low+1)
+			items[j] = fmt.Sprintf("%d-%d/%d", start, high, step)
+		}
+		fields[i] = strings.Join(items, ",")
+	}
+	return strings.Join(fields, " "), nil
+}
+
+// effectiveCron returns the cron expression a schedule of the Backup runs on,
+// with H tokens resolved from the Backup's namespace/name, plus the tier name
+// for schedule tiers
+func effectiveCron(backup *backupv1.Backup, schedule *backupv1.BackupSchedule, tier string) (string, error) {
+	seed := backup.Namespace + "/" + backup.Name
+	if tier != "" {
+		seed += "/" + tier
+	}
+	return resolveHashedCron(schedule.Cron, seed)
+}
+
+// validateCronExpression parses a schedule of a Backup and returns it together
+// with its effective cron expression
+func (r *BackupReconciler) validateCronExpression(backup *backupv1.Backup, schedule *backupv1.BackupSchedule, tier string) (cron.Schedule, string, error) {
+	expression, err := effectiveCron(backup, schedule, tier)
+	if err != nil {
+		return nil, "", err
+	}
+	timeZone := ""
+	if schedule.TimeZone != nil {
+		timeZone = *schedule.TimeZone
+	}
+	parsed, err := parseSchedule(expression, timeZone)
+	if err != nil {
+		return nil, "", err
+	}
+	return parsed, expression, nil
+}
+
+// reconcileSchedule validates the schedule of a Backup and records the result in
+// the ScheduleValid condition and status.nextScheduledTime. An invalid schedule
+// is reported in status only; it is not a reconcile error, since retrying
+// cannot fix it. Unscheduled Backups report neither. For Backups with schedule
+// tiers, every tier is validated and status.tiers gets an entry per tier.
+func (r *BackupReconciler) reconcileSchedule(ctx context.Context, backup *backupv1.Backup) (bool, error) {
+	original := backup.DeepCopy()
+	status := &backup.Status
+
+	var valid bool
+	var err error
+	switch {
+	case len(backup.Spec.Schedules) > 0:
+		err = r.reconcileTierSchedules(backup)
+		valid = err == nil
+	case hasSchedule(backup):
+		status.Tiers = nil
+		var schedule cron.Schedule
+		schedule, status.EffectiveSchedule, err = r.validateCronExpression(backup, backup.Spec.Schedule, "")
+		valid = err == nil
+		status.NextScheduledTime = nil
+		if valid && !isSuspended(backup.Spec.Schedule) {
+			next := metav1.NewTime(schedule.Next(time.Now()))
+			status.NextScheduledTime = &next
+		}
+	default:
+		meta.RemoveStatusCondition(&status.Conditions, ConditionScheduleValid)
+		status.NextScheduledTime = nil
+		status.EffectiveSchedule = ""
+		status.Tiers = nil
+	}
+
+	if err != nil {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               ConditionScheduleValid,
+			Status:             metav1.ConditionFalse,
+			Reason:             "InvalidSchedule",
+			Message:            truncateString(err.Error(), MaxMessageSize),
+			ObservedGeneration: backup.Generation,
+		})
+		status.NextScheduledTime = nil
+	} else if valid {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               ConditionScheduleValid,
+			Status:             metav1.ConditionTrue,
+			Reason:             "Valid",
+			Message:            "Schedule is valid",
+			ObservedGeneration: backup.Generation,
+		})
+	}
+
+	if equality.Semantic.DeepEqual(original.Status, backup.Status) {
+		return valid, nil
+	}
+	if err := r.Status().Patch(ctx, backup, client.MergeFrom(original)); err != nil {
+		return false, fmt.Errorf("failed to update schedule status: %w", err)
+	}
+	return valid, nil
+}
+
+// reconcileTierSchedules validates every schedule tier and rebuilds
+// status.tiers in spec order, keeping the run history of existing tiers. The
+// overall nextScheduledTime is the earliest next run of all tiers.
+func (r *BackupReconciler) reconcileTierSchedules(backup *backupv1.Backup) error {
+	status := &backup.Status
+	tiers := make([]backupv1.BackupTierStatus, 0, len(backup.Spec.Schedules))
+	status.NextScheduledTime = nil
+	status.EffectiveSchedule = ""
+
+	var invalid error
+	for i := range backup.Spec.Schedules {
+		tier := &backup.Spec.Schedules[i]
+		tierStatus := backupv1.BackupTierStatus{Name: tier.Name}
+		if existing := findTierStatus(status, tier.Name); existing != nil {
+			tierStatus = *existing
+		}
+		tierStatus.NextScheduledTime = nil
+
+		schedule, effective, err := r.validateCronExpression(backup, &tier.BackupSchedule, tier.Name)
+		tierStatus.EffectiveSchedule = effective
+		if err != nil {
+			if invalid == nil {
+				invalid = fmt.Errorf("tier %s: %w", tier.Name, err)
+			}
+		} else if !isSuspended(&tier.BackupSchedule) {
+			next := metav1.NewTime(schedule.Next(time.Now()))
+			tierStatus.NextScheduledTime = &next
+			if status.NextScheduledTime == nil || next.Before(status.NextScheduledTime) {
+				status.NextScheduledTime = &next
+			}
+		}
+		tiers = append(tiers, tierStatus)
+	}
+
+	status.Tiers = tiers
+	return invalid
+}
+
+// isSuspended reports whether a schedule is suspended
+func isSuspended(schedule *backupv1.BackupSchedule) bool {
+	return schedule.Suspend != nil && *schedule.Suspend
+}
+
+// findTierStatus returns the status entry of the named tier, or nil
+func findTierStatus(status *backupv1.BackupStatus, name string) *backupv1.BackupTierStatus {
+	for i := range status.Tiers {
+		if status.Tiers[i].Name == name {
+			return &status.Tiers[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+)
+
+func TestParseSchedule(t *testing.T) {
+	from := time.Date(2024, 6, 14, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		expression string
+		timeZone   string
+		// next is the run after from, in RFC 3339; "" expects an error
+		next string
+	}{
+		{name: "five fields", expression: "0 2 * * *", next: "2024-06-15T02:00:00Z"},
+		{name: "surrounding spaces", expression: " 30 4 * * 1-5 ", next: "2024-06-17T04:30:00Z"},
+		{name: "day names", expression: "0 2 * * SUN", next: "2024-06-16T02:00:00Z"},
+		{name: "time zone", expression: "0 2 * * *", timeZone: "Europe/Berlin", next: "2024-06-15T00:00:00Z"},
+		{name: "daily macro", expression: "@daily", next: "2024-06-15T00:00:00Z"},
+		{name: "weekly macro", expression: "@weekly", next: "2024-06-16T00:00:00Z"},
+		{name: "monthly macro in a time zone", expression: "@monthly", timeZone: "America/New_York", next: "2024-07-01T04:00:00Z"},
+		{name: "empty", expression: " "},
+		{name: "TZ prefix", expression: "TZ=UTC 0 2 * * *"},
+		{name: "CRON_TZ prefix", expression: "CRON_TZ=Europe/Berlin 0 2 * * *"},
+		{name: "every", expression: "@every 1h"},
+		{name: "four fields", expression: "0 2 * *"},
+		{name: "seconds field", expression: "0 0 2 * * *"},
+		{name: "out of range", expression: "0 24 * * *"},
+		{name: "never fires", expression: "0 0 30 2 *"},
+		{name: "unknown time zone", expression: "0 2 * * *", timeZone: "Mars/Olympus_Mons"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := parseSchedule(tt.expression, tt.timeZone)
+			if tt.next == "" {
+				if err == nil {
+					t.Fatalf("parseSchedule(%q, %q) succeeded, want an error", tt.expression, tt.timeZone)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSchedule(%q, %q) failed: %v", tt.expression, tt.timeZone, err)
+			}
+			if got := schedule.Next(from).UTC().Format(time.RFC3339); got != tt.next {
+				t.Errorf("next run of %q in %q = %s, want %s", tt.expression, tt.timeZone, got, tt.next)
+			}
+		})
+	}
+}
+
+func TestReconcileSchedule(t *testing.T) {
+	tests := []struct {
+		name  string
+		spec  backupv1.BackupSpec
+		valid bool
+		// next reports whether status.nextScheduledTime is set
+		next bool
+	}{
+		{name: "valid", spec: backupv1.BackupSpec{Schedule: &backupv1.BackupSchedule{Cron: "0 2 * * *"}}, valid: true, next: true},
+		{name: "never fires", spec: backupv1.BackupSpec{Schedule: &backupv1.BackupSchedule{Cron: "0 0 30 2 *"}}},
+		{name: "tier never fires", spec: backupv1.BackupSpec{Schedules: []backupv1.BackupScheduleTier{
+			{Name: "daily", BackupSchedule: backupv1.BackupSchedule{Cron: "0 2 * * *"}},
+			{Name: "leap", BackupSchedule: backupv1.BackupSchedule{Cron: "0 0 31 4 *"}},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backup := &backupv1.Backup{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"}, Spec: tt.spec}
+			c := newTestClient(t, backup)
+			r := &BackupReconciler{Client: c, Scheme: c.Scheme()}
+
+			valid, err := r.reconcileSchedule(context.Background(), backup)
+			if err != nil {
+				t.Fatalf("reconcileSchedule failed: %v", err)
+			}
+			if valid != tt.valid {
+				t.Errorf("valid = %v, want %v", valid, tt.valid)
+			}
+
+			got := &backupv1.Backup{}
+			if err := c.Get(context.Background(), client.ObjectKeyFromObject(backup), got); err != nil {
+				t.Fatalf("failed to get backup: %v", err)
+			}
+			if meta.IsStatusConditionTrue(got.Status.Conditions, ConditionScheduleValid) != tt.valid {
+				t.Errorf("conditions = %+v, want %s %v", got.Status.Conditions, ConditionScheduleValid, tt.valid)
+			}
+			if next := got.Status.NextScheduledTime; (next != nil) != tt.next || (next != nil && next.IsZero()) {
+				t.Errorf("nextScheduledTime = %v, want set: %v", next, tt.next)
+			}
+		})
+	}
+}
+
+func TestResolveHashedCron(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		want       string
+	}{
+		{name: "no H", expression: "0 2 * * *", want: "0 2 * * *"},
+		{name: "macro", expression: "@daily", want: "@daily"},
+		{name: "day name", expression: "0 2 * * THU", want: "0 2 * * THU"},
+		{name: "surrounding spaces", expression: "  30 4 * * 1-5 ", want: "30 4 * * 1-5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveHashedCron(tt.expression, "default/backup")
+			if err != nil {
+				t.Fatalf("resolveHashedCron(%q) failed: %v", tt.expression, err)
+			}
+			if got != tt.want {
+				t.Errorf("resolveHashedCron(%q) = %q, want %q", tt.expression, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveHashedCronValues(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		// pattern matches the result and captures the hashed value
+		pattern  string
+		min, max int
+	}{
+		{name: "minute", expression: "H 2 * * *", pattern: `^(\d+) 2 \* \* \*$`, min: 0, max: 59},
+		{name: "hour", expression: "0 H * * *", pattern: `^0 (\d+) \* \* \*$`, min: 0, max: 23},
+		{name: "day of month", expression: "0 0 H * *", pattern: `^0 0 (\d+) \* \*$`, min: 1, max: 28},
+		{name: "month", expression: "0 0 1 H *", pattern: `^0 0 1 (\d+) \*$`, min: 1, max: 12},
+		{name: "day of week", expression: "0 0 * * H", pattern: `^0 0 \* \* (\d+)$`, min: 0, max: 6},
+		{name: "range", expression: "H(10-20) 2 * * *", pattern: `^(\d+) 2 \* \* \*$`, min: 10, max: 20},
+		{name: "step", expression: "H/15 * * * *", pattern: `^(\d+)-59/15 \* \* \* \*$`, min: 0, max: 14},
+		{name: "range with step", expression: "H(0-29)/10 * * * *", pattern: `^(\d+)-29/10 \* \* \* \*$`, min: 0, max: 9},
+		{name: "list", expression: "0,H 2 * * *", pattern: `^0,(\d+) 2 \* \* \*$`, min: 0, max: 59},
+		{name: "surrounding spaces", expression: " H 2 * * * ", pattern: `^(\d+) 2 \* \* \*$`, min: 0, max: 59},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				seed := fmt.Sprintf("default/backup-%d", i)
+				got, err := resolveHashedCron(tt.expression, seed)
+				if err != nil {
+					t.Fatalf("resolveHashedCron(%q, %q) failed: %v", tt.expression, seed, err)
+				}
+				match := regexp.MustCompile(tt.pattern).FindStringSubmatch(got)
+				if match == nil {
+					t.Fatalf("resolveHashedCron(%q, %q) = %q, want a match of %s", tt.expression, seed, got, tt.pattern)
+				}
+				if value, _ := strconv.Atoi(match[1]); value < tt.min || value > tt.max {
+					t.Errorf("resolveHashedCron(%q, %q) = %q, want a value in %d-%d", tt.expression, seed, got, tt.min, tt.max)
+				}
+				if _, err := cronParser.Parse(got); err != nil {
+					t.Errorf("resolveHashedCron(%q, %q) = %q, which does not parse: %v", tt.expression, seed, got, err)
+				}
+				if again, _ := resolveHashedCron(tt.expression, seed); again != got {
+					t.Errorf("resolveHashedCron(%q, %q) = %q, then %q", tt.expression, seed, got, again)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveHashedCronSpreadsSeeds(t *testing.T) {
+	values := map[string]bool{}
+	for i := 0; i < 20; i++ {
+		got, err := resolveHashedCron("H * * * *", fmt.Sprintf("default/backup-%d", i))
+		if err != nil {
+			t.Fatalf("resolveHashedCron failed: %v", err)
+		}
+		values[got] = true
+	}
+	if len(values) < 2 {
+		t.Errorf("resolveHashedCron resolved 20 seeds to %v", values)
+	}
+}
+
+func TestResolveHashedCronErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+	}{
+		{name: "four fields", expression: "H 2 * *"},
+		{name: "six fields", expression: "0 H 2 * * *"},
+		{name: "range above bounds", expression: "H(50-70) * * * *"},
+		{name: "range below bounds", expression: "0 0 H(0-5) * *"},
+		{name: "reversed range", expression: "H(20-10) * * * *"},
+		{name: "zero step", expression: "H/0 * * * *"},
+		{name: "unsupported token", expression: "Hx * * * *"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := resolveHashedCron(tt.expression, "default/backup"); err == nil {
+				t.Errorf("resolveHashedCron(%q) = %q, want an error", tt.expression, got)
+			}
+		})
+	}
+}
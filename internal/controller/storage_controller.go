@@ -0,0 +1,154 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+	"github.com/gobackup/gobackup-operator/pkg/k8sutil"
+	"github.com/gobackup/gobackup-operator/pkg/storage"
+)
+
+// StorageReconciler reports in the status of Storages whether backups can be
+// uploaded to them, and which Backups reference them
+type StorageReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	K8s    *k8sutil.K8s
+}
+
+// +kubebuilder:rbac:groups=gobackup.io,resources=storages,verbs=get;list;watch
+// +kubebuilder:rbac:groups=gobackup.io,resources=storages/status,verbs=get;update;patch
+
+// Reconcile resolves the Secrets referenced by a Storage, checks its
+// configuration, and records the Ready and SecretsResolved conditions and
+// the Backups referencing it. Storages are checked again every
+// ReadinessCheckInterval.
+func (r *StorageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	store := &backupv1.Storage{}
+	if err := r.Get(ctx, req.NamespacedName, store); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !store.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	secretsErr := r.K8s.ResolveSecretReferences(ctx, store.Namespace, &store.Spec.Config)
+	var configErr error
+	if secretsErr == nil {
+		configErr = r.checkStorage(ctx, store)
+	}
+	backups, err := referencingBackups(ctx, r.Client, store.Namespace, func(backup *backupv1.Backup) bool {
+		return slices.ContainsFunc(backup.Spec.StorageRefs, func(ref backupv1.StorageRef) bool { return ref.Name == store.Name })
+	})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	original := store.DeepCopy()
+	store.Status.ObservedGeneration = store.Generation
+	store.Status.Backups = backups
+	setReadiness(&store.Status.Conditions, store.Generation, secretsErr, configErr)
+	if !equality.Semantic.DeepEqual(original.Status, store.Status) {
+		if err := r.Status().Patch(ctx, store, client.MergeFrom(original)); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to update storage status: %w", err)
+		}
+	}
+	return ctrl.Result{RequeueAfter: ReadinessCheckInterval}, nil
+}
+
+// checkStorage returns why backups cannot be uploaded to a Storage with
+// resolved Secrets, or nil. Only the configuration of the types the operator
+// lists is checked; the Storage is not contacted.
+func (r *StorageReconciler) checkStorage(ctx context.Context, store *backupv1.Storage) error {
+	storageType, config, err := r.K8s.ResolveStorage(ctx, store.Namespace, store.Name)
+	if err != nil {
+		return err
+	}
+	if !storage.IsSupported(storageType) {
+		return nil
+	}
+	_, err = storage.New(storageType, config)
+	return err
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *StorageReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&backupv1.Storage{}).
+		// Status updates of Backups do not change their references
+		Watches(&backupv1.Backup{}, handler.EnqueueRequestsFromMapFunc(r.findStoragesForBackup),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.findStoragesForSecret)).
+		Complete(r)
+}
+
+// findStoragesForBackup maps a Backup to the Storages it references or used
+// to reference
+func (r *StorageReconciler) findStoragesForBackup(ctx context.Context, obj client.Object) []ctrl.Request {
+	backup, ok := obj.(*backupv1.Backup)
+	if !ok {
+		return nil
+	}
+	storages := &backupv1.StorageList{}
+	if err := r.List(ctx, storages, client.InNamespace(backup.Namespace)); err != nil {
+		return nil
+	}
+
+	requests := []ctrl.Request{}
+	for _, store := range storages.Items {
+		referenced := slices.ContainsFunc(backup.Spec.StorageRefs, func(ref backupv1.StorageRef) bool { return ref.Name == store.Name })
+		if referenced || slices.Contains(store.Status.Backups, backup.Name) {
+			requests = append(requests, ctrl.Request{
+				NamespacedName: types.NamespacedName{Name: store.Name, Namespace: store.Namespace},
+			})
+		}
+	}
+	return requests
+}
+
+// findStoragesForSecret maps a Secret to the Storages of its namespace
+// referencing it
+func (r *StorageReconciler) findStoragesForSecret(ctx context.Context, obj client.Object) []ctrl.Request {
+	storages := &backupv1.StorageList{}
+	if err := r.List(ctx, storages, client.InNamespace(obj.GetNamespace())); err != nil {
+		return nil
+	}
+
+	requests := []ctrl.Request{}
+	for _, store := range storages.Items {
+		if slices.Contains(configSecretNames(&store.Spec.Config), obj.GetName()) {
+			requests = append(requests, ctrl.Request{
+				NamespacedName: types.NamespacedName{Name: store.Name, Namespace: store.Namespace},
+			})
+		}
+	}
+	return requests
+}
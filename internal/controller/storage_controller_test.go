@@ -0,0 +1,118 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+	"github.com/gobackup/gobackup-operator/pkg/k8sutil"
+)
+
+func TestStorageReconcileUnresolvedSecret(t *testing.T) {
+	store := &backupv1.Storage{ObjectMeta: metav1.ObjectMeta{Name: "s3", Namespace: "default", Generation: 2}}
+	store.Spec.Config.AccessKeyIDRef = &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "s3"}}
+	c := newTestClient(t, store,
+		testBackup("b", nil, []string{"s3"}),
+		testBackup("a", nil, []string{"s3", "local"}),
+		testBackup("c", nil, []string{"local"}),
+	)
+	r := &StorageReconciler{Client: c, Scheme: c.Scheme(), K8s: &k8sutil.K8s{}}
+
+	key := types.NamespacedName{Name: "s3", Namespace: "default"}
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+
+	got := &backupv1.Storage{}
+	if err := c.Get(context.Background(), key, got); err != nil {
+		t.Fatalf("failed to get storage: %v", err)
+	}
+	if got.Status.ObservedGeneration != got.Generation {
+		t.Errorf("observedGeneration = %d, want %d", got.Status.ObservedGeneration, got.Generation)
+	}
+	if want := []string{"a", "b"}; !slices.Equal(got.Status.Backups, want) {
+		t.Errorf("backups = %v, want %v", got.Status.Backups, want)
+	}
+	for _, condition := range []string{ConditionReady, ConditionSecretsResolved} {
+		if !meta.IsStatusConditionFalse(got.Status.Conditions, condition) {
+			t.Errorf("%s is not False: %+v", condition, meta.FindStatusCondition(got.Status.Conditions, condition))
+		}
+	}
+}
+
+func TestFindStoragesForBackup(t *testing.T) {
+	unreferenced := &backupv1.Storage{ObjectMeta: metav1.ObjectMeta{Name: "old", Namespace: "default"}}
+	unreferenced.Status.Backups = []string{"app"}
+	c := newTestClient(t,
+		&backupv1.Storage{ObjectMeta: metav1.ObjectMeta{Name: "s3", Namespace: "default"}},
+		&backupv1.Storage{ObjectMeta: metav1.ObjectMeta{Name: "s3", Namespace: "other"}},
+		&backupv1.Storage{ObjectMeta: metav1.ObjectMeta{Name: "local", Namespace: "default"}},
+		unreferenced,
+	)
+	r := &StorageReconciler{Client: c, Scheme: c.Scheme()}
+
+	tests := []struct {
+		name   string
+		backup *backupv1.Backup
+		want   []string
+	}{
+		{name: "referenced and previously referenced", backup: testBackup("app", nil, []string{"s3"}), want: []string{"old", "s3"}},
+		{name: "no references", backup: testBackup("other", nil, nil), want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := requestNames(r.findStoragesForBackup(context.Background(), tt.backup)); !slices.Equal(got, tt.want) {
+				t.Errorf("findStoragesForBackup = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindStoragesForSecret(t *testing.T) {
+	s3 := &backupv1.Storage{ObjectMeta: metav1.ObjectMeta{Name: "s3", Namespace: "default"}}
+	s3.Spec.Config.SecretAccessKeyRef = &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "creds"}, Key: "secret"}
+	sftp := &backupv1.Storage{ObjectMeta: metav1.ObjectMeta{Name: "sftp", Namespace: "default"}}
+	sftp.Spec.Config.PrivateKeyRef = &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "ssh"}, Key: "id_ed25519"}
+	c := newTestClient(t, s3, sftp)
+	r := &StorageReconciler{Client: c, Scheme: c.Scheme()}
+
+	tests := []struct {
+		name   string
+		secret string
+		want   []string
+	}{
+		{name: "referenced secret", secret: "creds", want: []string{"s3"}},
+		{name: "unreferenced secret", secret: "other", want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: tt.secret, Namespace: "default"}}
+			if got := requestNames(r.findStoragesForSecret(context.Background(), secret)); !slices.Equal(got, tt.want) {
+				t.Errorf("findStoragesForSecret = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+)
+
+const (
+	// WorkspaceMountPath is where the workspace volume is mounted; gobackup
+	// writes its temporary files there through TMPDIR
+	WorkspaceMountPath = "/workspace"
+
+	// workspaceSizeFactor leaves room for the dump, the archive and its
+	// compressed copy next to each other
+	workspaceSizeFactor = 3
+)
+
+// DefaultWorkspaceSize is the ephemeral workspace size before the Backup
+// has an archive to estimate from
+var DefaultWorkspaceSize = resource.MustParse("10Gi")
+
+// applyWorkspace mounts spec.workspace into the gobackup container of a pod
+func applyWorkspace(spec *corev1.PodSpec, backup *backupv1.Backup) {
+	workspace := backup.Spec.Workspace
+	if workspace == nil {
+		return
+	}
+
+	volume := corev1.Volume{Name: "workspace"}
+	switch {
+	case workspace.ClaimName != "":
+		volume.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{ClaimName: workspace.ClaimName}
+	case workspace.Ephemeral != nil:
+		size := DefaultWorkspaceSize
+		if workspace.Ephemeral.Size != nil {
+			size = *workspace.Ephemeral.Size
+		} else if backup.Status.WorkspaceSize != nil {
+			size = *backup.Status.WorkspaceSize
+		}
+		volume.Ephemeral = &corev1.EphemeralVolumeSource{
+			VolumeClaimTemplate: &corev1.PersistentVolumeClaimTemplate{
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					StorageClassName: workspace.Ephemeral.StorageClassName,
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: size},
+					},
+				},
+			},
+		}
+	default:
+		return
+	}
+	spec.Volumes = append(spec.Volumes, volume)
+
+	container := &spec.Containers[0]
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{Name: "workspace", MountPath: WorkspaceMountPath})
+	container.Env = append(container.Env, corev1.EnvVar{Name: "TMPDIR", Value: WorkspaceMountPath})
+}
+
+// reconcileWorkspaceSize records in status.workspaceSize the size of an
+// ephemeral workspace without an explicit size, estimated from the newest
+// archive of the Backup. Rounding to powers of two keeps the CronJob from
+// being updated after every run.
+func (r *BackupReconciler) reconcileWorkspaceSize(ctx context.Context, backup *backupv1.Backup) error {
+	workspace := backup.Spec.Workspace
+	var size *resource.Quantity
+	if workspace != nil && workspace.Ephemeral != nil && workspace.Ephemeral.Size == nil {
+		artifacts := &backupv1.BackupArtifactList{}
+		if err := r.List(ctx, artifacts, client.InNamespace(backup.Namespace), client.MatchingLabels{BackupLabel: backup.Name}); err != nil {
+			return fmt.Errorf("failed to list backup artifacts: %w", err)
+		}
+		var newest *backupv1.BackupArtifact
+		for i := range artifacts.Items {
+			artifact := &artifacts.Items[i]
+			if artifact.Spec.Timestamp == nil || artifact.Spec.Size == 0 {
+				continue
+			}
+			if newest == nil || newest.Spec.Timestamp.Before(artifact.Spec.Timestamp) {
+				newest = artifact
+			}
+		}
+		estimate := DefaultWorkspaceSize.DeepCopy()
+		if newest != nil {
+			estimate = estimateWorkspaceSize(newest.Spec.Size)
+		}
+		size = &estimate
+	}
+
+	current := backup.Status.WorkspaceSize
+	if (current == nil && size == nil) || (current != nil && size != nil && current.Cmp(*size) == 0) {
+		return nil
+	}
+	patch := client.MergeFrom(backup.DeepCopy())
+	backup.Status.WorkspaceSize = size
+	if err := r.Status().Patch(ctx, backup, patch); err != nil {
+		return fmt.Errorf("failed to update workspace size: %w", err)
+	}
+	return nil
+}
+
+// estimateWorkspaceSize returns workspaceSizeFactor times the archive size,
+// rounded up to a power of two GiB
+func estimateWorkspaceSize(archiveSize int64) resource.Quantity {
+	needed := archiveSize * workspaceSizeFactor
+	gib := int64(1)
+	for gib<<30 < needed {
+		gib <<= 1
+	}
+	return resource.MustParse(fmt.Sprintf("%dGi", gib))
+}
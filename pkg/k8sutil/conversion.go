@@ -0,0 +1,66 @@
+package k8sutil
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// The access to DatabaseCRDName is not generated from markers: only the
+// chart needs it, and grants it when webhook.enabled is set. The kustomize
+// deployment patches the CRD itself.
+
+// DatabaseCRDName is the name of the CustomResourceDefinition of Databases
+const DatabaseCRDName = "databases.gobackup.io"
+
+// EnableDatabaseConversion serves gobackup.io/v2 Databases, converted by the
+// webhook Service of the operator. It is for installs, such as the Helm
+// chart, whose CRDs cannot be patched with the Service and its CA bundle.
+func (k *K8s) EnableDatabaseConversion(ctx context.Context, namespace, service string, caBundle []byte) error {
+	gvr := schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+	crd, err := k.DynamicClient.Resource(gvr).Get(ctx, DatabaseCRDName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get CustomResourceDefinition %s: %w", DatabaseCRDName, err)
+	}
+
+	versions, _, err := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	if err != nil {
+		return fmt.Errorf("invalid versions of CustomResourceDefinition %s: %w", DatabaseCRDName, err)
+	}
+	for _, version := range versions {
+		if version, ok := version.(map[string]interface{}); ok && version["name"] == "v2" {
+			version["served"] = true
+		}
+	}
+	if err := unstructured.SetNestedSlice(crd.Object, versions, "spec", "versions"); err != nil {
+		return err
+	}
+
+	conversion := map[string]interface{}{
+		"strategy": "Webhook",
+		"webhook": map[string]interface{}{
+			"conversionReviewVersions": []interface{}{"v1"},
+			"clientConfig": map[string]interface{}{
+				"caBundle": base64.StdEncoding.EncodeToString(caBundle),
+				"service": map[string]interface{}{
+					"name":      service,
+					"namespace": namespace,
+					"path":      "/convert",
+					"port":      int64(443),
+				},
+			},
+		},
+	}
+	if err := unstructured.SetNestedField(crd.Object, conversion, "spec", "conversion"); err != nil {
+		return err
+	}
+
+	if _, err := k.DynamicClient.Resource(gvr).Update(ctx, crd, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update CustomResourceDefinition %s: %w", DatabaseCRDName, err)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package k8sutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+	backupv2 "github.com/gobackup/gobackup-operator/api/v2"
+)
+
+// typedDatabase converts a Database, fetched unstructured in the v1 storage
+// version, to its typed v2 form
+func typedDatabase(databaseCRD *unstructured.Unstructured) (*backupv2.Database, error) {
+	stored := &backupv1.Database{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(databaseCRD.Object, stored); err != nil {
+		return nil, fmt.Errorf("database %s is not valid: %w", databaseCRD.GetName(), err)
+	}
+	database := &backupv2.Database{}
+	if err := database.ConvertFrom(stored); err != nil {
+		return nil, err
+	}
+	return database, nil
+}
+
+// renderDatabase returns the gobackup type and options of a Database, with
+// its Secret references resolved. It returns the endpoint of the engine, if
+// any, for the caller to resolve serviceRef and fromObject.
+func (k *K8s) renderDatabase(ctx context.Context, database *backupv2.Database) (string, map[string]interface{}, *backupv2.Endpoint, error) {
+	namespace := database.Namespace
+	config := make(map[string]interface{})
+
+	var dbType string
+	var endpoint *backupv2.Endpoint
+	var credentials *backupv2.Credentials
+	switch spec := database.Spec; {
+	case spec.PostgreSQL != nil:
+		e := spec.PostgreSQL
+		dbType, endpoint, credentials = "postgresql", &e.Endpoint, &e.Credentials
+		setOption(config, "database", e.Database)
+		setList(config, "tables", e.Tables)
+		setList(config, "exclude_tables", e.ExcludeTables)
+		setOption(config, "args", e.Args())
+	case spec.MySQL != nil, spec.MariaDB != nil:
+		e := spec.MySQL
+		dbType = "mysql"
+		if spec.MariaDB != nil {
+			dbType, e = "mariadb", spec.MariaDB
+		}
+		endpoint, credentials = &e.Endpoint, &e.Credentials
+		setOption(config, "database", e.Database)
+		setList(config, "tables", e.Tables)
+		setList(config, "exclude_tables", e.ExcludeTables)
+		setOption(config, "args", e.Args())
+	case spec.MongoDB != nil:
+		e := spec.MongoDB
+		dbType, endpoint, credentials = "mongodb", &e.Endpoint, &e.Credentials
+		setOption(config, "database", e.Database)
+		setOption(config, "authdb", e.AuthDB)
+		setOption(config, "oplog", e.Oplog)
+		setOption(config, "args", e.Args())
+	case spec.Redis != nil:
+		e := spec.Redis
+		dbType, endpoint = "redis", &e.Endpoint
+		if err := k.setSecretOption(ctx, namespace, config, "password", e.Password, e.PasswordRef); err != nil {
+			return "", nil, nil, err
+		}
+		setOption(config, "mode", e.Mode)
+		setOption(config, "invoke_save", e.InvokeSave)
+		setOption(config, "rdb_path", e.RdbPath)
+		setOption(config, "args_redis", e.ExtraArgs)
+	case spec.MSSQL != nil:
+		e := spec.MSSQL
+		dbType, endpoint, credentials = "mssql", &e.Endpoint, &e.Credentials
+		setOption(config, "database", e.Database)
+		setOption(config, "trust_server_certificate", e.TrustServerCertificate)
+		setOption(config, "args", e.ExtraArgs)
+	case spec.InfluxDB != nil:
+		e := spec.InfluxDB
+		dbType, endpoint = "influxdb", &e.Endpoint
+		if err := k.setSecretOption(ctx, namespace, config, "token", e.Token, e.TokenRef); err != nil {
+			return "", nil, nil, err
+		}
+		setOption(config, "bucket", e.Bucket)
+		setOption(config, "org", e.Organization)
+	case spec.ETCD != nil:
+		e := spec.ETCD
+		dbType = "etcd"
+		setList(config, "endpoints", e.Endpoints)
+		setOption(config, "args", e.Args())
+	default:
+		return "", nil, nil, fmt.Errorf("database %s sets no engine", database.Name)
+	}
+
+	if endpoint != nil {
+		setOption(config, "host", endpoint.Host)
+		setOption(config, "port", endpoint.Port)
+		setOption(config, "socket", endpoint.Socket)
+	}
+	if credentials != nil {
+		if err := k.setSecretOption(ctx, namespace, config, "username", credentials.Username, credentials.UsernameRef); err != nil {
+			return "", nil, nil, err
+		}
+		if err := k.setSecretOption(ctx, namespace, config, "password", credentials.Password, credentials.PasswordRef); err != nil {
+			return "", nil, nil, err
+		}
+	}
+
+	// Options written through v1 that the engine has no field for are
+	// passed to gobackup as they were
+	if leftovers := database.Annotations[backupv2.V1ConfigAnnotation]; leftovers != "" {
+		legacy := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(leftovers), &legacy); err != nil {
+			return "", nil, nil, fmt.Errorf("invalid %s annotation: %w", backupv2.V1ConfigAnnotation, err)
+		}
+		for key, value := range legacy {
+			if value == nil {
+				delete(legacy, key)
+			}
+		}
+		resolved, err := k.resolveSecretReferences(ctx, namespace, legacy)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		for key, value := range resolved {
+			config[key] = value
+		}
+	}
+
+	return dbType, config, endpoint, nil
+}
+
+// setOption sets a gobackup option when value is set
+func setOption[T any](config map[string]interface{}, key string, value *T) {
+	if value != nil {
+		config[key] = *value
+	}
+}
+
+// setList sets a gobackup option when values is not empty
+func setList(config map[string]interface{}, key string, values []string) {
+	if len(values) > 0 {
+		config[key] = values
+	}
+}
+
+// setSecretOption sets a gobackup option to value, or to the value of the
+// Secret key ref selects
+func (k *K8s) setSecretOption(ctx context.Context, namespace string, config map[string]interface{}, key string, value *string, ref *corev1.SecretKeySelector) error {
+	if ref == nil {
+		setOption(config, key, value)
+		return nil
+	}
+	secretValue, err := k.secretKeyValue(ctx, namespace, *ref)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", key, err)
+	}
+	config[key] = secretValue
+	return nil
+}
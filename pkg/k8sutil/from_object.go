@@ -0,0 +1,120 @@
+package k8sutil
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/jsonpath"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+)
+
+// resolveFromObject reads the fields mapped by the fromObject of a Database
+// from an object in its namespace. It returns nil when fromObject is not set.
+func (k *K8s) resolveFromObject(ctx context.Context, namespace string, ref *backupv1.DatabaseObjectRef) (map[string]interface{}, error) {
+	if ref == nil {
+		return nil, nil
+	}
+
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fromObject.apiVersion %s: %w", ref.APIVersion, err)
+	}
+	resource, err := k.resourceFor(gv, ref.Kind)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := k.GetCRD(ctx, gv.Group, gv.Version, resource, namespace, ref.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	source := obj.Object
+	if gv.Group == "" && ref.Kind == "Secret" {
+		source = decodeSecretData(source)
+	}
+
+	values := make(map[string]interface{})
+	for field, expr := range ref.FieldPaths {
+		result, err := evalFieldPath(source, field, expr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from %s %s: %w", field, ref.Kind, ref.Name, err)
+		}
+		if field != "port" {
+			values[field] = result
+			continue
+		}
+		port, err := strconv.Atoi(result)
+		if err != nil {
+			return nil, fmt.Errorf("port %q read from %s %s is not a number", result, ref.Kind, ref.Name)
+		}
+		values[field] = port
+	}
+	return values, nil
+}
+
+// resourceFor returns the resource name of a kind, as served by the API server
+func (k *K8s) resourceFor(gv schema.GroupVersion, kind string) (string, error) {
+	resources, err := k.Clientset.Discovery().ServerResourcesForGroupVersion(gv.String())
+	if err != nil {
+		return "", fmt.Errorf("failed to discover resources of %s: %w", gv, err)
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Kind == kind && !strings.Contains(resource.Name, "/") {
+			return resource.Name, nil
+		}
+	}
+	return "", fmt.Errorf("kind %s is not served by %s", kind, gv)
+}
+
+// decodeSecretData returns a copy of an unstructured Secret with the values of
+// data decoded
+func decodeSecretData(secret map[string]interface{}) map[string]interface{} {
+	data, ok := secret["data"].(map[string]interface{})
+	if !ok {
+		return secret
+	}
+	decoded := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		encoded, _ := value.(string)
+		raw, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			continue
+		}
+		decoded[key] = string(raw)
+	}
+	source := make(map[string]interface{}, len(secret))
+	for key, value := range secret {
+		source[key] = value
+	}
+	source["data"] = decoded
+	return source
+}
+
+// evalFieldPath evaluates a JSONPath expression, with or without braces,
+// against an object and returns the single value it selects
+func evalFieldPath(obj map[string]interface{}, field, expr string) (string, error) {
+	if !strings.HasPrefix(expr, "{") {
+		expr = "{" + expr + "}"
+	}
+	path := jsonpath.New(field)
+	if err := path.Parse(expr); err != nil {
+		return "", fmt.Errorf("invalid field path %s: %w", expr, err)
+	}
+	results, err := path.FindResults(obj)
+	if err != nil {
+		return "", err
+	}
+	if len(results) == 0 || len(results[0]) == 0 {
+		return "", fmt.Errorf("field path %s selects nothing", expr)
+	}
+	value := results[0][0].Interface()
+	if value == nil {
+		return "", fmt.Errorf("field path %s selects null", expr)
+	}
+	return fmt.Sprint(value), nil
+}
@@ -0,0 +1,122 @@
+package k8sutil
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+	"github.com/gobackup/gobackup-operator/pkg/rclone"
+)
+
+// RestoreSecretName returns the name of the Secret holding the environment of a
+// restore Job
+func RestoreSecretName(restore *backupv1.Restore) string {
+	return restore.Name + "-restore"
+}
+
+// CreateRestoreSecret creates or updates the Secret owned by the Restore with
+// the environment of its restore Job: the rclone remote for the Storage, the
+// connection of the target Database and the decryption password. It returns
+// the type of the target Database.
+func (k *K8s) CreateRestoreSecret(ctx context.Context, restore *backupv1.Restore) (string, error) {
+	if restore == nil {
+		return "", fmt.Errorf("restore cannot be nil")
+	}
+	namespace := restore.Namespace
+
+	dbType, dbConfig, err := k.ResolveDatabase(ctx, namespace, restore.Spec.DatabaseRef.Name)
+	if err != nil {
+		return "", err
+	}
+
+	storageType, storageConfig, err := k.ResolveStorage(ctx, namespace, restore.Spec.StorageRef.Name)
+	if err != nil {
+		return "", fmt.Errorf("failed to get storage %s: %w", restore.Spec.StorageRef.Name, err)
+	}
+
+	env, err := rclone.Env(storageType, storageConfig)
+	if err != nil {
+		return "", err
+	}
+	sourcePath := rclone.RemotePath(storageType, storageConfig)
+	if sourcePath != "" && !strings.HasSuffix(sourcePath, "/") {
+		sourcePath += "/"
+	}
+	env["SOURCE_PATH"] = rclone.Remote + ":" + sourcePath
+
+	for key, option := range map[string]string{
+		"DB_HOST":      "host",
+		"DB_PORT":      "port",
+		"DB_USERNAME":  "username",
+		"DB_PASSWORD":  "password",
+		"DB_DATABASE":  "database",
+		"DB_AUTH_DB":   "authdb",
+		"DB_ENDPOINTS": "endpoints",
+	} {
+		if value := configString(dbConfig[option]); value != "" {
+			env[key] = value
+		}
+	}
+
+	if encode := restore.Spec.EncodeWith; encode != nil {
+		password, err := k.secretKeyValue(ctx, namespace, encode.PasswordRef)
+		if err != nil {
+			return "", fmt.Errorf("failed to get decryption password: %w", err)
+		}
+		env["DECRYPT_PASSWORD"] = password
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      RestoreSecretName(restore),
+			Namespace: namespace,
+		},
+		StringData: env,
+	}
+
+	ownerRef := metav1.NewControllerRef(restore, backupv1.GroupVersion.WithKind("Restore"))
+	if err := k.applySecret(ctx, secret, ownerRef); err != nil {
+		return "", err
+	}
+
+	return dbType, nil
+}
+
+// secretKeyValue returns the value of a key in a Secret
+func (k *K8s) secretKeyValue(ctx context.Context, namespace string, selector corev1.SecretKeySelector) (string, error) {
+	secret, err := k.Clientset.CoreV1().Secrets(namespace).Get(ctx, selector.Name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return "", fmt.Errorf("secret %s not found", selector.Name)
+		}
+		return "", fmt.Errorf("failed to get secret %s: %w", selector.Name, err)
+	}
+
+	value, ok := secret.Data[selector.Key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in secret %s", selector.Key, selector.Name)
+	}
+	return string(value), nil
+}
+
+// configString renders a config value for an environment variable. Lists, such
+// as etcd endpoints, are joined with commas.
+func configString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -2,13 +2,19 @@ package k8sutil
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"path"
+	"slices"
 	"strings"
 
 	yaml "gopkg.in/yaml.v2"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
 )
@@ -20,7 +26,7 @@ type BackupConfig struct {
 
 // Model represents the configuration for a backup model
 type Model struct {
-	Databases map[string]interface{} `yaml:"databases"`
+	Databases map[string]interface{} `yaml:"databases,omitempty"`
 	Storages  map[string]interface{} `yaml:"storages"`
 
 	// Optional fields
@@ -30,12 +36,56 @@ type Model struct {
 	// Compression and encryption
 	Compress string `yaml:"compress_with,omitempty"`
 	Encode   string `yaml:"encode_with,omitempty"`
+
+	// Archive holds the files of PersistentVolumeClaims to archive
+	Archive *Archive `yaml:"archive,omitempty"`
+}
+
+// Archive represents the archive section of a gobackup model
+type Archive struct {
+	Includes []string `yaml:"includes,omitempty"`
+	Excludes []string `yaml:"excludes,omitempty"`
+}
+
+// ArchiveMountPath returns where the claim of an archive is mounted in backup Jobs
+func ArchiveMountPath(claimName string) string {
+	return path.Join("/archives", claimName)
 }
 
-// CreateSecret creates or updates the gobackup.yml Secret owned by the Backup resource
-func (k *K8s) CreateSecret(ctx context.Context, backup *backupv1.Backup) error {
+// ExecDumpPath is where the init containers of backup Jobs write the dumps of
+// exec-mode Databases, one file per Database
+const ExecDumpPath = "/dumps"
+
+// buildArchive renders spec.archives, with paths made absolute, and the
+// dumps of exec-mode Databases, or nil
+func buildArchive(archives []backupv1.BackupArchive, execDumps bool) *Archive {
+	if len(archives) == 0 && !execDumps {
+		return nil
+	}
+	archive := &Archive{}
+	if execDumps {
+		archive.Includes = append(archive.Includes, ExecDumpPath)
+	}
+	for _, source := range archives {
+		root := ArchiveMountPath(source.ClaimName)
+		if len(source.Includes) == 0 {
+			archive.Includes = append(archive.Includes, root)
+		}
+		for _, include := range source.Includes {
+			archive.Includes = append(archive.Includes, path.Join(root, include))
+		}
+		for _, exclude := range source.Excludes {
+			archive.Excludes = append(archive.Excludes, path.Join(root, exclude))
+		}
+	}
+	return archive
+}
+
+// CreateSecret creates or updates the gobackup.yml Secret owned by the Backup
+// resource, and returns the hash of the rendered configuration
+func (k *K8s) CreateSecret(ctx context.Context, backup *backupv1.Backup) (string, error) {
 	if backup == nil {
-		return fmt.Errorf("backup cannot be nil")
+		return "", fmt.Errorf("backup cannot be nil")
 	}
 
 	model := backup.Spec
@@ -44,61 +94,24 @@ func (k *K8s) CreateSecret(ctx context.Context, backup *backupv1.Backup) error {
 
 	databases := make(map[string]interface{})
 	storages := make(map[string]interface{})
+	// retained holds the storages pruned by the operator instead of gobackup
+	retained := make(map[string]bool)
+	execDumps := false
 
 	// Process database references
 	for _, database := range model.DatabaseRefs {
-		// Resource name is always "databases" (plural of Database kind), not type-specific
-		resource := "databases"
-
-		// Default to gobackup.io if APIGroup is not specified
-		apiGroup := database.APIGroup
-		if apiGroup == "" {
-			apiGroup = "gobackup.io"
-		}
-
-		// Fetch the database CRD
-		databaseCRD, err := k.GetCRD(ctx, apiGroup, "v1", resource, namespace, database.Name)
+		databaseCRD, err := k.getDatabase(ctx, namespace, database.APIGroup, database.Name)
 		if err != nil {
-			return fmt.Errorf("failed to get database %s: %w", database.Name, err)
-		}
-
-		// Extract the database spec
-		specMap, ok := databaseCRD.Object["spec"].(map[string]interface{})
-		if !ok {
-			return fmt.Errorf("database spec for %s is not a valid map", database.Name)
+			return "", err
 		}
-
-		rawType, ok := specMap["type"].(string)
-		if !ok || strings.TrimSpace(rawType) == "" {
-			return fmt.Errorf("database type for %s is missing or invalid", database.Name)
-		}
-		dbType := strings.ToLower(strings.TrimSpace(rawType))
-
-		// Extract config if it exists
-		configMap, ok := specMap["config"].(map[string]interface{})
-		if !ok {
-			return fmt.Errorf("database config for %s is not a valid map", database.Name)
+		// Exec-mode Databases are dumped by the init containers of the Job
+		if _, ok, _ := unstructured.NestedFieldNoCopy(databaseCRD.Object, "spec", "exec"); ok {
+			execDumps = true
+			continue
 		}
-
-		// Resolve secret references in the config
-		resolvedConfig, err := k.resolveSecretReferences(ctx, namespace, configMap)
+		dbType, dbConfig, _, err := k.databaseConfig(ctx, databaseCRD)
 		if err != nil {
-			return fmt.Errorf("failed to resolve secret references for database %s: %w", database.Name, err)
-		}
-
-		// Convert field names to the format expected by gobackup (snake_case)
-		dbConfig := make(map[string]interface{})
-		for key, value := range resolvedConfig {
-			// Convert camelCase to snake_case for applicable fields
-			switch key {
-			case "excludeTables":
-				dbConfig["exclude_tables"] = value
-			case "additionalOptions":
-				dbConfig["additional_options"] = value
-			default:
-				// Keep snake_case fields as-is, or convert if needed
-				dbConfig[key] = value
-			}
+			return "", err
 		}
 
 		// Set the database type explicitly
@@ -111,57 +124,10 @@ func (k *K8s) CreateSecret(ctx context.Context, backup *backupv1.Backup) error {
 	// Process storage references
 	for _, storage := range model.StorageRefs {
 		storageType := strings.ToLower(storage.Type)
-		// Resource name is always "storages" (plural of Storage kind), not type-specific
-		resource := "storages"
 
-		// Default to gobackup.io if APIGroup is not specified
-		apiGroup := storage.APIGroup
-		if apiGroup == "" {
-			apiGroup = "gobackup.io"
-		}
-
-		// Fetch the storage CRD
-		storageCRD, err := k.GetCRD(ctx, apiGroup, "v1", resource, namespace, storage.Name)
+		_, storageConfig, err := k.resolveStorageConfig(ctx, namespace, storage.APIGroup, storage.Name)
 		if err != nil {
-			return fmt.Errorf("failed to get %s storage: %w", storageType, err)
-		}
-
-		// Extract the storage spec
-		specMap, ok := storageCRD.Object["spec"].(map[string]interface{})
-		if !ok {
-			return fmt.Errorf("storage spec for %s is not a valid map", storage.Name)
-		}
-
-		// Extract config if it exists
-		configMap, ok := specMap["config"].(map[string]interface{})
-		if !ok {
-			return fmt.Errorf("storage config for %s is not a valid map", storage.Name)
-		}
-
-		// Resolve secret references in the config
-		resolvedConfig, err := k.resolveSecretReferences(ctx, namespace, configMap)
-		if err != nil {
-			return fmt.Errorf("failed to resolve secret references for storage %s: %w", storage.Name, err)
-		}
-
-		// Convert field names to the format expected by gobackup (snake_case)
-		storageConfig := make(map[string]interface{})
-		for key, value := range resolvedConfig {
-			// Convert camelCase to snake_case for applicable fields
-			switch key {
-			case "accessKeyID":
-				storageConfig["access_key_id"] = value
-			case "secretAccessKey":
-				storageConfig["secret_access_key"] = value
-			case "forcePathStyle":
-				storageConfig["force_path_style"] = value
-			case "storageClass":
-				storageConfig["storage_class"] = value
-			case "maxRetries":
-				storageConfig["max_retries"] = value
-			default:
-				storageConfig[key] = value
-			}
+			return "", fmt.Errorf("failed to get %s storage: %w", storageType, err)
 		}
 
 		// Set the storage type explicitly
@@ -174,6 +140,10 @@ func (k *K8s) CreateSecret(ctx context.Context, backup *backupv1.Backup) error {
 		if storage.Timeout > 0 {
 			storageConfig["timeout"] = storage.Timeout
 		}
+		if storage.Retention != nil {
+			delete(storageConfig, "keep")
+			retained[storage.Name] = true
+		}
 
 		// Add to storages map
 		storages[storage.Name] = storageConfig
@@ -183,6 +153,7 @@ func (k *K8s) CreateSecret(ctx context.Context, backup *backupv1.Backup) error {
 	backupModel := Model{
 		Databases: databases,
 		Storages:  storages,
+		Archive:   buildArchive(model.Archives, execDumps),
 	}
 
 	// Add optional fields if provided
@@ -203,24 +174,36 @@ func (k *K8s) CreateSecret(ctx context.Context, backup *backupv1.Backup) error {
 		backupModel.Encode = model.EncodeWith.Type
 	}
 
-	// Create the backup config
+	// Create the backup config. Backups with schedule tiers get one model per
+	// tier, so each CronJob performs only its own model with its own retention.
 	backupConfig := BackupConfig{
-		Models: map[string]Model{
-			name: backupModel,
-		},
+		Models: map[string]Model{},
+	}
+	if len(model.Schedules) == 0 {
+		backupConfig.Models[name] = backupModel
+	}
+	for _, tier := range model.Schedules {
+		tierModel := backupModel
+		tierModel.Storages = tierStorages(storages, retained, tier)
+		if len(tierModel.Storages) == 0 {
+			return "", fmt.Errorf("schedule tier %s references no storage of the backup", tier.Name)
+		}
+		backupConfig.Models[TierModelName(backup, tier.Name)] = tierModel
 	}
 
 	// Marshal to YAML
 	yamlData, err := yaml.Marshal(&backupConfig)
 	if err != nil {
-		return fmt.Errorf("failed to marshal backup config: %w", err)
+		return "", fmt.Errorf("failed to marshal backup config: %w", err)
 	}
 
 	// Create the Secret object
+	configHash := ConfigHash(yamlData)
 	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: namespace,
+			Name:        name,
+			Namespace:   namespace,
+			Annotations: map[string]string{ConfigHashAnnotation: configHash},
 		},
 		StringData: map[string]string{
 			"gobackup.yml": string(yamlData),
@@ -228,12 +211,32 @@ func (k *K8s) CreateSecret(ctx context.Context, backup *backupv1.Backup) error {
 	}
 
 	ownerRef := metav1.NewControllerRef(backup, backupv1.GroupVersion.WithKind("Backup"))
+	if err := k.applySecret(ctx, secret, ownerRef); err != nil {
+		return "", err
+	}
+	return configHash, nil
+}
+
+// ConfigHashAnnotation is set on the gobackup.yml Secret of a Backup to the
+// ConfigHash of its content, so edits of the Secret can be detected
+const ConfigHashAnnotation = "gobackup.io/config-hash"
+
+// ConfigHash returns the hash of a rendered gobackup configuration
+func ConfigHash(config []byte) string {
+	sum := sha256.Sum256(config)
+	return hex.EncodeToString(sum[:])
+}
+
+// applySecret creates the Secret, or replaces the data of an existing one and
+// makes sure it is owned by ownerRef
+func (k *K8s) applySecret(ctx context.Context, secret *corev1.Secret, ownerRef *metav1.OwnerReference) error {
+	namespace := secret.Namespace
 	if ownerRef != nil {
 		secret.OwnerReferences = append(secret.OwnerReferences, *ownerRef)
 	}
 
 	// Check if the secret already exists
-	found, err := k.Clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	found, err := k.Clientset.CoreV1().Secrets(namespace).Get(ctx, secret.Name, metav1.GetOptions{})
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// Create the Secret
@@ -247,7 +250,14 @@ func (k *K8s) CreateSecret(ctx context.Context, backup *backupv1.Backup) error {
 	}
 
 	existing := found.DeepCopy()
+	existing.Data = nil
 	existing.StringData = secret.StringData
+	for k, v := range secret.Annotations {
+		if existing.Annotations == nil {
+			existing.Annotations = map[string]string{}
+		}
+		existing.Annotations[k] = v
+	}
 	if ownerRef != nil {
 		existing.OwnerReferences = ensureOwnerReference(existing.OwnerReferences, *ownerRef)
 	}
@@ -260,6 +270,187 @@ func (k *K8s) CreateSecret(ctx context.Context, backup *backupv1.Backup) error {
 	return nil
 }
 
+// TierModelName returns the gobackup model name of a schedule tier of a Backup
+func TierModelName(backup *backupv1.Backup, tier string) string {
+	return backup.Name + "-" + tier
+}
+
+// tierStorages returns the storages of a schedule tier, uploading to the
+// <path>/<tier> directory so that the keep of a tier only counts its own
+// archives, with the tier's keep override applied to those not pruned by the
+// operator
+func tierStorages(storages map[string]interface{}, retained map[string]bool, tier backupv1.BackupScheduleTier) map[string]interface{} {
+	selected := make(map[string]interface{})
+	for name, config := range storages {
+		if len(tier.StorageRefs) > 0 && !slices.Contains(tier.StorageRefs, name) {
+			continue
+		}
+		tierConfig := make(map[string]interface{})
+		for key, value := range config.(map[string]interface{}) {
+			tierConfig[key] = value
+		}
+		storagePath, _ := tierConfig["path"].(string)
+		tierConfig["path"] = path.Join(storagePath, tier.Name)
+		if tier.Keep != nil && !retained[name] {
+			tierConfig["keep"] = *tier.Keep
+		}
+		selected[name] = tierConfig
+	}
+	return selected
+}
+
+// ResolveDatabase fetches a Database resource and returns its type together with
+// its config, with secret references resolved and field names in the form
+// gobackup expects.
+func (k *K8s) ResolveDatabase(ctx context.Context, namespace, name string) (string, map[string]interface{}, error) {
+	return k.resolveDatabaseConfig(ctx, namespace, "", name)
+}
+
+// ResolveStorage fetches a Storage resource and returns its type together with
+// its config, with secret references resolved and field names in the form
+// gobackup expects.
+func (k *K8s) ResolveStorage(ctx context.Context, namespace, name string) (string, map[string]interface{}, error) {
+	return k.resolveStorageConfig(ctx, namespace, "", name)
+}
+
+// resolveDatabaseConfig fetches the Database resource and converts its config
+func (k *K8s) resolveDatabaseConfig(ctx context.Context, namespace, apiGroup, name string) (string, map[string]interface{}, error) {
+	databaseCRD, err := k.getDatabase(ctx, namespace, apiGroup, name)
+	if err != nil {
+		return "", nil, err
+	}
+	dbType, dbConfig, _, err := k.databaseConfig(ctx, databaseCRD)
+	return dbType, dbConfig, err
+}
+
+// ResolveServiceTarget renders a Database as Backups would and returns where
+// its serviceRef points, or nil when it has none
+func (k *K8s) ResolveServiceTarget(ctx context.Context, namespace, name string) (*backupv1.ServiceTarget, error) {
+	databaseCRD, err := k.getDatabase(ctx, namespace, "", name)
+	if err != nil {
+		return nil, err
+	}
+	_, _, target, err := k.databaseConfig(ctx, databaseCRD)
+	return target, err
+}
+
+// getDatabase fetches a Database resource
+func (k *K8s) getDatabase(ctx context.Context, namespace, apiGroup, name string) (*unstructured.Unstructured, error) {
+	// Resource name is always "databases" (plural of Database kind), not type-specific
+	resource := "databases"
+
+	// Default to gobackup.io if APIGroup is not specified
+	if apiGroup == "" {
+		apiGroup = "gobackup.io"
+	}
+
+	// Fetch the database CRD
+	databaseCRD, err := k.GetCRD(ctx, apiGroup, "v1", resource, namespace, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database %s: %w", name, err)
+	}
+	return databaseCRD, nil
+}
+
+// databaseConfig returns the type of a Database and its config, rendered from
+// its typed v2 form with secret references, fromObject and serviceRef
+// resolved, together with the target of serviceRef
+func (k *K8s) databaseConfig(ctx context.Context, databaseCRD *unstructured.Unstructured) (string, map[string]interface{}, *backupv1.ServiceTarget, error) {
+	namespace, name := databaseCRD.GetNamespace(), databaseCRD.GetName()
+
+	database, err := typedDatabase(databaseCRD)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	dbType, dbConfig, endpoint, err := k.renderDatabase(ctx, database)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("failed to resolve secret references for database %s: %w", name, err)
+	}
+
+	var target *backupv1.ServiceTarget
+	if endpoint != nil {
+		// Take the fields mapped by fromObject from the upstream object
+		mapped, err := k.resolveFromObject(ctx, namespace, endpoint.FromObject)
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("failed to resolve fromObject of database %s: %w", name, err)
+		}
+		for key, value := range mapped {
+			dbConfig[key] = value
+		}
+
+		// Point host and port at the Service of serviceRef
+		target, err = k.resolveServiceRef(ctx, namespace, endpoint.ServiceRef)
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("failed to resolve service of database %s: %w", name, err)
+		}
+		if target != nil {
+			dbConfig["host"] = target.Host
+			dbConfig["port"] = target.Port
+		}
+	}
+	return dbType, dbConfig, target, nil
+}
+
+// resolveStorageConfig fetches the Storage resource and converts its config
+func (k *K8s) resolveStorageConfig(ctx context.Context, namespace, apiGroup, name string) (string, map[string]interface{}, error) {
+	// Resource name is always "storages" (plural of Storage kind), not type-specific
+	resource := "storages"
+
+	// Default to gobackup.io if APIGroup is not specified
+	if apiGroup == "" {
+		apiGroup = "gobackup.io"
+	}
+
+	// Fetch the storage CRD
+	storageCRD, err := k.GetCRD(ctx, apiGroup, "v1", resource, namespace, name)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Extract the storage spec
+	specMap, ok := storageCRD.Object["spec"].(map[string]interface{})
+	if !ok {
+		return "", nil, fmt.Errorf("storage spec for %s is not a valid map", name)
+	}
+
+	storageType, _ := specMap["type"].(string)
+	storageType = strings.ToLower(strings.TrimSpace(storageType))
+
+	// Extract config if it exists
+	configMap, ok := specMap["config"].(map[string]interface{})
+	if !ok {
+		return "", nil, fmt.Errorf("storage config for %s is not a valid map", name)
+	}
+
+	// Resolve secret references in the config
+	resolvedConfig, err := k.resolveSecretReferences(ctx, namespace, configMap)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to resolve secret references for storage %s: %w", name, err)
+	}
+
+	// Convert field names to the format expected by gobackup (snake_case)
+	storageConfig := make(map[string]interface{})
+	for key, value := range resolvedConfig {
+		// Convert camelCase to snake_case for applicable fields
+		switch key {
+		case "accessKeyID":
+			storageConfig["access_key_id"] = value
+		case "secretAccessKey":
+			storageConfig["secret_access_key"] = value
+		case "forcePathStyle":
+			storageConfig["force_path_style"] = value
+		case "storageClass":
+			storageConfig["storage_class"] = value
+		case "maxRetries":
+			storageConfig["max_retries"] = value
+		default:
+			storageConfig[key] = value
+		}
+	}
+
+	return storageType, storageConfig, nil
+}
+
 func ensureOwnerReference(refs []metav1.OwnerReference, owner metav1.OwnerReference) []metav1.OwnerReference {
 	for _, ref := range refs {
 		if ref.UID == owner.UID {
@@ -269,6 +460,18 @@ func ensureOwnerReference(refs []metav1.OwnerReference, owner metav1.OwnerRefere
 	return append(refs, owner)
 }
 
+// ResolveSecretReferences resolves the Secret references of the config of a
+// Database or Storage, given as a pointer to its typed config, and returns the
+// first that cannot be resolved
+func (k *K8s) ResolveSecretReferences(ctx context.Context, namespace string, config interface{}) error {
+	configMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(config)
+	if err != nil {
+		return fmt.Errorf("failed to convert config: %w", err)
+	}
+	_, err = k.resolveSecretReferences(ctx, namespace, configMap)
+	return err
+}
+
 // resolveSecretReferences resolves secret references in a config map.
 // It looks for fields ending with "_ref" (e.g., access_key_id_ref, password_ref),
 // fetches the referenced secrets, and replaces the "_ref" fields with the actual values.
@@ -298,7 +501,7 @@ func (k *K8s) resolveSecretReferences(ctx context.Context, namespace string, con
 			secret, err := k.Clientset.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
 			if err != nil {
 				if errors.IsNotFound(err) {
-					return nil, fmt.Errorf("secret %s referenced by %s not found", secretName, key)
+					return nil, fmt.Errorf("secret %s referenced by %s not found: %w", secretName, key, err)
 				}
 				return nil, fmt.Errorf("failed to get secret %s: %w", secretName, err)
 			}
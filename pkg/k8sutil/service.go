@@ -0,0 +1,121 @@
+package k8sutil
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	backupv1 "github.com/gobackup/gobackup-operator/api/v1"
+)
+
+// resolveServiceRef resolves the serviceRef of a Database to a host and port,
+// or returns nil when it is not set
+func (k *K8s) resolveServiceRef(ctx context.Context, namespace string, ref *backupv1.DatabaseServiceRef) (*backupv1.ServiceTarget, error) {
+	if ref == nil {
+		return nil, nil
+	}
+	if ref.Namespace != "" {
+		namespace = ref.Namespace
+	}
+
+	service, err := k.Clientset.CoreV1().Services(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service %s/%s: %w", namespace, ref.Name, err)
+	}
+	port, err := servicePort(service, ref.Port)
+	if err != nil {
+		return nil, err
+	}
+
+	target := &backupv1.ServiceTarget{
+		Host: fmt.Sprintf("%s.%s.svc", service.Name, service.Namespace),
+		Port: port.Port,
+	}
+	if ref.ReplicaSelector == nil {
+		return target, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(ref.ReplicaSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid replicaSelector: %w", err)
+	}
+	pods, err := k.Clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replica pods: %w", err)
+	}
+	replicas := map[string]bool{}
+	for _, pod := range pods.Items {
+		replicas[pod.Name] = true
+	}
+
+	endpointSlices, err := k.Clientset.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: discoveryv1.LabelServiceName + "=" + service.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list endpoint slices of service %s: %w", service.Name, err)
+	}
+	if replica := pickReplica(endpointSlices.Items, port, replicas); replica != nil {
+		return replica, nil
+	}
+	target.Message = "no ready endpoint matches replicaSelector"
+	return target, nil
+}
+
+// servicePort returns the port of a Service selected by name or number, or
+// its only port
+func servicePort(service *corev1.Service, port *intstr.IntOrString) (*corev1.ServicePort, error) {
+	for i := range service.Spec.Ports {
+		candidate := &service.Spec.Ports[i]
+		switch {
+		case port == nil && len(service.Spec.Ports) == 1,
+			port != nil && port.Type == intstr.String && candidate.Name == port.StrVal,
+			port != nil && port.Type == intstr.Int && candidate.Port == port.IntVal:
+			return candidate, nil
+		}
+	}
+	if port == nil {
+		return nil, fmt.Errorf("service %s has %d ports; set serviceRef.port", service.Name, len(service.Spec.Ports))
+	}
+	return nil, fmt.Errorf("service %s has no port %s", service.Name, port.String())
+}
+
+// pickReplica returns the ready endpoint, first by pod name, of the replica
+// pods serving the port of a Service, or nil
+func pickReplica(endpointSlices []discoveryv1.EndpointSlice, port *corev1.ServicePort, replicas map[string]bool) *backupv1.ServiceTarget {
+	var targets []backupv1.ServiceTarget
+	for _, slice := range endpointSlices {
+		var targetPort *int32
+		for _, candidate := range slice.Ports {
+			if candidate.Name != nil && *candidate.Name == port.Name {
+				targetPort = candidate.Port
+			}
+		}
+		if targetPort == nil {
+			continue
+		}
+		for _, endpoint := range slice.Endpoints {
+			ready := endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready
+			if !ready || endpoint.TargetRef == nil || endpoint.TargetRef.Kind != "Pod" ||
+				!replicas[endpoint.TargetRef.Name] || len(endpoint.Addresses) == 0 {
+				continue
+			}
+			host := endpoint.Addresses[0]
+			// Pods of a StatefulSet keep their DNS name across restarts
+			if endpoint.Hostname != nil {
+				host = fmt.Sprintf("%s.%s.%s.svc", *endpoint.Hostname, slice.Labels[discoveryv1.LabelServiceName], slice.Namespace)
+			}
+			targets = append(targets, backupv1.ServiceTarget{Host: host, Port: *targetPort, Pod: endpoint.TargetRef.Name})
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+	target := slices.MinFunc(targets, func(a, b backupv1.ServiceTarget) int { return strings.Compare(a.Pod, b.Pod) })
+	return &target
+}
@@ -0,0 +1,150 @@
+package rclone
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Remote is the name of the rclone remote configured by Env
+const Remote = "source"
+
+// PlainPasswordSuffix marks environment variables holding a password that still
+// has to be obscured with `rclone obscure` before rclone can use it. Scripts
+// export RCLONE_CONFIG_SOURCE_<NAME> from <NAME>_PLAIN for each of them.
+const PlainPasswordSuffix = "_PLAIN"
+
+// s3Providers maps S3-compatible storage types to the rclone s3 provider
+var s3Providers = map[string]string{
+	"s3":     "AWS",
+	"minio":  "Minio",
+	"r2":     "Cloudflare",
+	"spaces": "DigitalOcean",
+	"oss":    "Alibaba",
+	"cos":    "TencentCOS",
+	"obs":    "HuaweiOBS",
+	"b2":     "Other",
+	"tos":    "Other",
+	"us3":    "Other",
+	"kodo":   "Qiniu",
+	"bos":    "Other",
+}
+
+// Env returns the environment variables that configure an rclone remote named
+// Remote for a Storage of the given type. config is the Storage config with
+// secret references already resolved, keyed like gobackup's storage options.
+func Env(storageType string, config map[string]interface{}) (map[string]string, error) {
+	env := map[string]string{}
+	set := func(option string, value string) {
+		if value != "" {
+			env["RCLONE_CONFIG_"+strings.ToUpper(Remote)+"_"+strings.ToUpper(option)] = value
+		}
+	}
+	setPassword := func(option string, value string) {
+		if value != "" {
+			env[strings.ToUpper(option)+PlainPasswordSuffix] = value
+		}
+	}
+
+	switch storageType {
+	case "local":
+		set("type", "local")
+	case "sftp", "scp":
+		set("type", "sftp")
+		set("host", str(config, "host"))
+		set("port", str(config, "port"))
+		set("user", str(config, "username"))
+		setPassword("pass", str(config, "password"))
+		// private_key holds the key itself when it came from private_key_ref,
+		// otherwise it is a path inside the container
+		if key := str(config, "private_key"); strings.HasPrefix(strings.TrimSpace(key), "-----BEGIN") {
+			set("key_pem", key)
+		} else {
+			set("key_file", key)
+		}
+		setPassword("key_file_pass", str(config, "passphrase"))
+	case "ftp":
+		set("type", "ftp")
+		set("host", str(config, "host"))
+		set("port", str(config, "port"))
+		set("user", str(config, "username"))
+		setPassword("pass", str(config, "password"))
+	case "webdav":
+		set("type", "webdav")
+		set("url", str(config, "root"))
+		set("user", str(config, "username"))
+		setPassword("pass", str(config, "password"))
+	case "gcs":
+		set("type", "google cloud storage")
+		set("service_account_credentials", str(config, "credentials"))
+		set("service_account_file", str(config, "credentials_file"))
+	case "azure":
+		set("type", "azureblob")
+		account := str(config, "account")
+		if account == "" {
+			account = str(config, "bucket")
+		}
+		set("account", account)
+		set("tenant", str(config, "tenant_id"))
+		set("client_id", str(config, "client_id"))
+		set("client_secret", str(config, "client_secret"))
+	default:
+		provider, ok := s3Providers[storageType]
+		if !ok {
+			return nil, fmt.Errorf("storage type %q is not supported for downloads", storageType)
+		}
+		endpoint := str(config, "endpoint")
+		switch {
+		case endpoint != "":
+		case storageType == "r2":
+			endpoint = fmt.Sprintf("https://%s.r2.cloudflarestorage.com", str(config, "account_id"))
+		case storageType == "spaces":
+			endpoint = fmt.Sprintf("%s.digitaloceanspaces.com", orDefault(str(config, "region"), "nyc1"))
+		case provider == "Other":
+			return nil, fmt.Errorf("storage type %q requires an endpoint for downloads", storageType)
+		}
+		set("type", "s3")
+		set("provider", provider)
+		set("access_key_id", str(config, "access_key_id"))
+		set("secret_access_key", str(config, "secret_access_key"))
+		set("region", str(config, "region"))
+		set("endpoint", endpoint)
+		if str(config, "force_path_style") == "true" {
+			set("force_path_style", "true")
+		}
+	}
+
+	return env, nil
+}
+
+// RemotePath returns the rclone path, relative to Remote, of the directory the
+// Storage uploads archives to.
+func RemotePath(storageType string, config map[string]interface{}) string {
+	dir := strings.Trim(str(config, "path"), "/")
+	switch storageType {
+	case "local":
+		return "/" + dir
+	case "sftp", "scp", "ftp", "webdav":
+		return dir
+	case "azure":
+		return path.Join(str(config, "container"), dir)
+	default:
+		return path.Join(str(config, "bucket"), dir)
+	}
+}
+
+// str returns a config value as a string, or "" when it is not set
+func str(config map[string]interface{}, key string) string {
+	value, ok := config[key]
+	if !ok || value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+func orDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
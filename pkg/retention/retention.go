@@ -0,0 +1,100 @@
+package retention
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Policy selects the archives to keep. An archive is kept when any of the
+// count rules selects it and it is not older than MaxAge. Zero values disable
+// a rule; with no count rule at all, every archive within MaxAge is kept.
+type Policy struct {
+	// KeepLast keeps the newest archives
+	KeepLast int
+	// KeepDaily keeps the newest archive of each of the most recent days
+	KeepDaily int
+	// KeepWeekly keeps the newest archive of each of the most recent ISO weeks
+	KeepWeekly int
+	// KeepMonthly keeps the newest archive of each of the most recent months
+	KeepMonthly int
+	// KeepYearly keeps the newest archive of each of the most recent years
+	KeepYearly int
+	// MaxAge removes archives older than this, whatever the count rules say
+	MaxAge time.Duration
+}
+
+// Archive is a backup archive in a storage
+type Archive struct {
+	Key  string
+	Time time.Time
+}
+
+// Apply splits archives into those the policy keeps and those to delete, both
+// ordered newest first. Calendar periods are evaluated in UTC.
+func Apply(policy Policy, archives []Archive, now time.Time) (keep, drop []Archive) {
+	sorted := make([]Archive, len(archives))
+	copy(sorted, archives)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.After(sorted[j].Time)
+	})
+
+	hasCountRule := policy.KeepLast > 0 || policy.KeepDaily > 0 || policy.KeepWeekly > 0 ||
+		policy.KeepMonthly > 0 || policy.KeepYearly > 0
+	selected := make([]bool, len(sorted))
+	if !hasCountRule {
+		for i := range selected {
+			selected[i] = true
+		}
+	}
+
+	for i := 0; i < len(sorted) && i < policy.KeepLast; i++ {
+		selected[i] = true
+	}
+	selectPeriods(sorted, selected, policy.KeepDaily, func(t time.Time) string {
+		return t.Format("2006-01-02")
+	})
+	selectPeriods(sorted, selected, policy.KeepWeekly, func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	})
+	selectPeriods(sorted, selected, policy.KeepMonthly, func(t time.Time) string {
+		return t.Format("2006-01")
+	})
+	selectPeriods(sorted, selected, policy.KeepYearly, func(t time.Time) string {
+		return t.Format("2006")
+	})
+
+	for i, archive := range sorted {
+		if policy.MaxAge > 0 && now.Sub(archive.Time) > policy.MaxAge {
+			selected[i] = false
+		}
+		if selected[i] {
+			keep = append(keep, archive)
+		} else {
+			drop = append(drop, archive)
+		}
+	}
+	return keep, drop
+}
+
+// selectPeriods marks the newest archive of each of the count most recent
+// periods that have an archive. archives must be sorted newest first.
+func selectPeriods(archives []Archive, selected []bool, count int, period func(time.Time) string) {
+	if count <= 0 {
+		return
+	}
+	last := ""
+	for i, archive := range archives {
+		p := period(archive.Time.UTC())
+		if p == last {
+			continue
+		}
+		last = p
+		selected[i] = true
+		count--
+		if count == 0 {
+			return
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package retention
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestApply(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		policy   Policy
+		archives []string
+		keep     []string
+		drop     []string
+	}{
+		{
+			name:     "no rules keeps everything",
+			archives: []string{"2024-06-13T01:00:00Z", "2024-06-15T01:00:00Z", "2024-06-14T01:00:00Z"},
+			keep:     []string{"2024-06-15T01:00:00Z", "2024-06-14T01:00:00Z", "2024-06-13T01:00:00Z"},
+		},
+		{
+			name:     "keepLast keeps the newest",
+			policy:   Policy{KeepLast: 2},
+			archives: []string{"2024-06-13T01:00:00Z", "2024-06-15T01:00:00Z", "2024-06-14T01:00:00Z"},
+			keep:     []string{"2024-06-15T01:00:00Z", "2024-06-14T01:00:00Z"},
+			drop:     []string{"2024-06-13T01:00:00Z"},
+		},
+		{
+			name:   "keepDaily keeps the newest archive of each day",
+			policy: Policy{KeepDaily: 2},
+			archives: []string{"2024-06-15T01:00:00Z", "2024-06-15T07:00:00Z",
+				"2024-06-14T01:00:00Z", "2024-06-14T07:00:00Z", "2024-06-13T07:00:00Z"},
+			keep: []string{"2024-06-15T07:00:00Z", "2024-06-14T07:00:00Z"},
+			drop: []string{"2024-06-15T01:00:00Z", "2024-06-14T01:00:00Z", "2024-06-13T07:00:00Z"},
+		},
+		{
+			name:     "keepWeekly uses ISO weeks",
+			policy:   Policy{KeepWeekly: 2},
+			archives: []string{"2024-12-29T01:00:00Z", "2024-12-30T01:00:00Z", "2025-01-01T01:00:00Z"},
+			keep:     []string{"2025-01-01T01:00:00Z", "2024-12-29T01:00:00Z"},
+			drop:     []string{"2024-12-30T01:00:00Z"},
+		},
+		{
+			name:     "keepMonthly skips months without archives",
+			policy:   Policy{KeepMonthly: 2},
+			archives: []string{"2024-06-01T01:00:00Z", "2024-04-20T01:00:00Z", "2024-04-10T01:00:00Z", "2024-03-01T01:00:00Z"},
+			keep:     []string{"2024-06-01T01:00:00Z", "2024-04-20T01:00:00Z"},
+			drop:     []string{"2024-04-10T01:00:00Z", "2024-03-01T01:00:00Z"},
+		},
+		{
+			name:     "keepYearly",
+			policy:   Policy{KeepYearly: 1},
+			archives: []string{"2024-01-01T01:00:00Z", "2023-12-31T01:00:00Z"},
+			keep:     []string{"2024-01-01T01:00:00Z"},
+			drop:     []string{"2023-12-31T01:00:00Z"},
+		},
+		{
+			name:     "rules combine",
+			policy:   Policy{KeepLast: 1, KeepMonthly: 2},
+			archives: []string{"2024-06-15T01:00:00Z", "2024-06-14T01:00:00Z", "2024-05-31T01:00:00Z", "2024-05-01T01:00:00Z"},
+			keep:     []string{"2024-06-15T01:00:00Z", "2024-05-31T01:00:00Z"},
+			drop:     []string{"2024-06-14T01:00:00Z", "2024-05-01T01:00:00Z"},
+		},
+		{
+			name:     "days are evaluated in UTC",
+			policy:   Policy{KeepDaily: 2},
+			archives: []string{"2024-06-15T01:00:00+02:00", "2024-06-14T10:00:00Z", "2024-06-13T10:00:00Z"},
+			keep:     []string{"2024-06-15T01:00:00+02:00", "2024-06-13T10:00:00Z"},
+			drop:     []string{"2024-06-14T10:00:00Z"},
+		},
+		{
+			name:     "maxAge overrides count rules",
+			policy:   Policy{KeepLast: 3, MaxAge: 36 * time.Hour},
+			archives: []string{"2024-06-15T01:00:00Z", "2024-06-14T01:00:00Z", "2024-06-13T01:00:00Z"},
+			keep:     []string{"2024-06-15T01:00:00Z", "2024-06-14T01:00:00Z"},
+			drop:     []string{"2024-06-13T01:00:00Z"},
+		},
+		{
+			name:     "maxAge alone keeps recent archives",
+			policy:   Policy{MaxAge: 12 * time.Hour},
+			archives: []string{"2024-06-15T01:00:00Z", "2024-06-14T01:00:00Z"},
+			keep:     []string{"2024-06-15T01:00:00Z"},
+			drop:     []string{"2024-06-14T01:00:00Z"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archives := make([]Archive, 0, len(tt.archives))
+			for _, key := range tt.archives {
+				archiveTime, err := time.Parse(time.RFC3339, key)
+				if err != nil {
+					t.Fatalf("failed to parse %s: %v", key, err)
+				}
+				archives = append(archives, Archive{Key: key, Time: archiveTime})
+			}
+
+			keep, drop := Apply(tt.policy, archives, now)
+			if got := keys(keep); !reflect.DeepEqual(got, tt.keep) {
+				t.Errorf("keep = %v, want %v", got, tt.keep)
+			}
+			if got := keys(drop); !reflect.DeepEqual(got, tt.drop) {
+				t.Errorf("drop = %v, want %v", got, tt.drop)
+			}
+		})
+	}
+}
+
+// keys returns the keys of archives, or nil
+func keys(archives []Archive) []string {
+	var keys []string
+	for _, archive := range archives {
+		keys = append(keys, archive.Key)
+	}
+	return keys
+}
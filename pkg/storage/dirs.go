@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+)
+
+// dirs lists a storage path together with directories under it
+type dirs struct {
+	root Client
+	dirs map[string]Client
+}
+
+// NewWithDirs returns a Client for a Storage of the given type that also
+// covers the named directories under its path, such as those of schedule
+// tiers. Files in a directory are keyed "<dir>/<name>"; directories that do
+// not exist yet are listed as empty.
+func NewWithDirs(storageType string, config map[string]interface{}, names []string) (Client, error) {
+	root, err := New(storageType, config)
+	if err != nil {
+		return nil, err
+	}
+	d := &dirs{root: root, dirs: map[string]Client{}}
+	for _, name := range names {
+		dirConfig := make(map[string]interface{}, len(config))
+		for key, value := range config {
+			dirConfig[key] = value
+		}
+		dirConfig["path"] = path.Join(configString(config, "path"), name)
+		if d.dirs[name], err = New(storageType, dirConfig); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+func (d *dirs) List(ctx context.Context) ([]Object, error) {
+	objects, err := d.root.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for name, dir := range d.dirs {
+		dirObjects, err := dir.List(ctx)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range dirObjects {
+			object.Key = name + "/" + object.Key
+			objects = append(objects, object)
+		}
+	}
+	return objects, nil
+}
+
+func (d *dirs) Delete(ctx context.Context, key string) error {
+	name, file := path.Split(key)
+	if name == "" {
+		return d.root.Delete(ctx, key)
+	}
+	dir, ok := d.dirs[path.Clean(name)]
+	if !ok {
+		return fmt.Errorf("failed to delete %s: unknown directory %s", key, name)
+	}
+	return dir.Delete(ctx, file)
+}
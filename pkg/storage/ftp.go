@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/textproto"
+	"path"
+	"strconv"
+
+	"github.com/jlaffaye/ftp"
+)
+
+// ftpStorage lists a directory on an FTP server
+type ftpStorage struct {
+	addr     string
+	username string
+	password string
+	path     string
+}
+
+func newFTP(config map[string]interface{}) (*ftpStorage, error) {
+	host := configString(config, "host")
+	if host == "" {
+		return nil, fmt.Errorf("ftp storage requires a host")
+	}
+	return &ftpStorage{
+		addr:     net.JoinHostPort(host, strconv.Itoa(configInt(config, "port", 21))),
+		username: configString(config, "username"),
+		password: configString(config, "password"),
+		path:     configString(config, "path"),
+	}, nil
+}
+
+// connect opens a logged in FTP connection; close it with Quit
+func (f *ftpStorage) connect(ctx context.Context) (*ftp.ServerConn, error) {
+	conn, err := ftp.Dial(f.addr, ftp.DialWithContext(ctx), ftp.DialWithTimeout(defaultTimeout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", f.addr, err)
+	}
+	if err := conn.Login(f.username, f.password); err != nil {
+		_ = conn.Quit()
+		return nil, fmt.Errorf("failed to log in to %s: %w", f.addr, err)
+	}
+	return conn, nil
+}
+
+func (f *ftpStorage) List(ctx context.Context) ([]Object, error) {
+	conn, err := f.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Quit() }()
+
+	entries, err := conn.List(f.path)
+	if err != nil {
+		var protoErr *textproto.Error
+		if errors.As(err, &protoErr) && protoErr.Code == ftp.StatusFileUnavailable {
+			return nil, fmt.Errorf("failed to list directory %s: %w", f.path, fs.ErrNotExist)
+		}
+		return nil, fmt.Errorf("failed to list directory %s: %w", f.path, err)
+	}
+
+	objects := []Object{}
+	for _, entry := range entries {
+		if entry.Type != ftp.EntryTypeFile {
+			continue
+		}
+		objects = append(objects, Object{
+			Key:          entry.Name,
+			Size:         int64(entry.Size),
+			LastModified: entry.Time,
+		})
+	}
+	return objects, nil
+}
+
+func (f *ftpStorage) Delete(ctx context.Context, key string) error {
+	conn, err := f.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Quit() }()
+
+	if err := conn.Delete(path.Join(f.path, path.Base(key))); err != nil {
+		// FTP reports a missing file as permanent error 550
+		var protoErr *textproto.Error
+		if errors.As(err, &protoErr) && protoErr.Code == ftp.StatusFileUnavailable {
+			return nil
+		}
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	return nil
+}
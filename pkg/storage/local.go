@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// local lists a directory mounted into the operator
+type local struct {
+	path string
+}
+
+func newLocal(config map[string]interface{}) (*local, error) {
+	path := configString(config, "path")
+	if path == "" {
+		return nil, fmt.Errorf("local storage requires a path")
+	}
+	return &local{path: path}, nil
+}
+
+func (l *local) List(ctx context.Context) ([]Object, error) {
+	entries, err := os.ReadDir(l.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", l.path, err)
+	}
+
+	objects := []Object{}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		objects = append(objects, Object{
+			Key:          entry.Name(),
+			Size:         info.Size(),
+			LastModified: info.ModTime(),
+		})
+	}
+	return objects, nil
+}
+
+func (l *local) Delete(ctx context.Context, key string) error {
+	if err := os.Remove(filepath.Join(l.path, filepath.Base(key))); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// s3Compatible maps S3-compatible storage types to their default endpoint.
+// An empty default means the Storage must set an endpoint.
+var s3Compatible = map[string]string{
+	"s3":     "s3.amazonaws.com",
+	"minio":  "",
+	"r2":     "",
+	"spaces": "",
+	"b2":     "",
+	"oss":    "",
+	"cos":    "",
+	"obs":    "",
+	"tos":    "",
+	"us3":    "",
+	"kodo":   "",
+	"bos":    "",
+}
+
+// s3 lists a bucket prefix of an S3-compatible service
+type s3 struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+func newS3(storageType string, config map[string]interface{}) (*s3, error) {
+	bucket := configString(config, "bucket")
+	if bucket == "" {
+		return nil, fmt.Errorf("%s storage requires a bucket", storageType)
+	}
+
+	endpoint := configString(config, "endpoint")
+	switch {
+	case endpoint != "":
+	case storageType == "r2" && configString(config, "account_id") != "":
+		endpoint = configString(config, "account_id") + ".r2.cloudflarestorage.com"
+	case storageType == "spaces":
+		region := configString(config, "region")
+		if region == "" {
+			region = "nyc1"
+		}
+		endpoint = region + ".digitaloceanspaces.com"
+	default:
+		endpoint = s3Compatible[storageType]
+	}
+	if endpoint == "" {
+		return nil, fmt.Errorf("%s storage requires an endpoint", storageType)
+	}
+
+	// Endpoints may be given as URLs; minio wants host[:port] and a TLS flag
+	secure := true
+	if u, err := url.Parse(endpoint); err == nil && u.Host != "" {
+		secure = u.Scheme != "http"
+		endpoint = u.Host
+	}
+
+	lookup := minio.BucketLookupAuto
+	if configBool(config, "force_path_style") || storageType == "minio" {
+		lookup = minio.BucketLookupPath
+	}
+
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds: credentials.NewStaticV4(
+			configString(config, "access_key_id"),
+			configString(config, "secret_access_key"),
+			"",
+		),
+		Secure:       secure,
+		Region:       configString(config, "region"),
+		BucketLookup: lookup,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s client: %w", storageType, err)
+	}
+
+	prefix := configPath(config)
+	if prefix != "" {
+		prefix += "/"
+	}
+	return &s3{client: client, bucket: bucket, prefix: prefix}, nil
+}
+
+func (s *s3) List(ctx context.Context) ([]Object, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	objects := []Object{}
+	for info := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: s.prefix}) {
+		if info.Err != nil {
+			return nil, fmt.Errorf("failed to list bucket %s: %w", s.bucket, info.Err)
+		}
+		if strings.HasSuffix(info.Key, "/") {
+			continue
+		}
+
+		object := Object{
+			Key:          path.Base(info.Key),
+			Size:         info.Size,
+			LastModified: info.LastModified,
+		}
+		// The ETag is the MD5 of the content, except for multipart uploads
+		if etag := strings.Trim(info.ETag, `"`); len(etag) == 32 && !strings.Contains(etag, "-") {
+			object.Checksum = "md5:" + etag
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+func (s *s3) Delete(ctx context.Context, key string) error {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	// Deleting a missing object succeeds in S3
+	if err := s.client.RemoveObject(ctx, s.bucket, s.prefix+path.Base(key), minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to delete %s from bucket %s: %w", key, s.bucket, err)
+	}
+	return nil
+}
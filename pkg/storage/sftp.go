@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// sftpStorage lists a directory on an SSH server. scp storages are listed the
+// same way, as gobackup uploads to them over the same SSH connection.
+type sftpStorage struct {
+	addr   string
+	config *ssh.ClientConfig
+	path   string
+}
+
+func newSFTP(config map[string]interface{}) (*sftpStorage, error) {
+	host := configString(config, "host")
+	if host == "" {
+		return nil, fmt.Errorf("sftp storage requires a host")
+	}
+
+	auth := []ssh.AuthMethod{}
+	if key := configString(config, "private_key"); key != "" {
+		pem := []byte(key)
+		// private_key is the key itself when it came from private_key_ref,
+		// otherwise a path readable by the operator
+		if !strings.HasPrefix(strings.TrimSpace(key), "-----BEGIN") {
+			data, err := os.ReadFile(key)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read private key: %w", err)
+			}
+			pem = data
+		}
+
+		var signer ssh.Signer
+		var err error
+		if passphrase := configString(config, "passphrase"); passphrase != "" {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase(pem, []byte(passphrase))
+		} else {
+			signer, err = ssh.ParsePrivateKey(pem)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key: %w", err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if password := configString(config, "password"); password != "" {
+		auth = append(auth, ssh.Password(password))
+	}
+
+	return &sftpStorage{
+		addr: net.JoinHostPort(host, strconv.Itoa(configInt(config, "port", 22))),
+		config: &ssh.ClientConfig{
+			User: configString(config, "username"),
+			Auth: auth,
+			// gobackup does not verify host keys either
+			HostKeyCallback: ssh.InsecureIgnoreHostKey(), //nolint:gosec
+			Timeout:         defaultTimeout,
+		},
+		path: configString(config, "path"),
+	}, nil
+}
+
+// connect opens an SFTP session; close it with the returned function
+func (s *sftpStorage) connect() (*sftp.Client, func(), error) {
+	conn, err := ssh.Dial("tcp", s.addr, s.config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to %s: %w", s.addr, err)
+	}
+
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, nil, fmt.Errorf("failed to start sftp session: %w", err)
+	}
+	return client, func() {
+		_ = client.Close()
+		_ = conn.Close()
+	}, nil
+}
+
+func (s *sftpStorage) dir() string {
+	if s.path == "" {
+		return "."
+	}
+	return s.path
+}
+
+func (s *sftpStorage) List(ctx context.Context) ([]Object, error) {
+	client, closeClient, err := s.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer closeClient()
+
+	dir := s.dir()
+	entries, err := client.ReadDirContext(ctx, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+
+	objects := []Object{}
+	for _, entry := range entries {
+		if !entry.Mode().IsRegular() {
+			continue
+		}
+		objects = append(objects, Object{
+			Key:          entry.Name(),
+			Size:         entry.Size(),
+			LastModified: entry.ModTime(),
+		})
+	}
+	return objects, nil
+}
+
+func (s *sftpStorage) Delete(ctx context.Context, key string) error {
+	client, closeClient, err := s.connect()
+	if err != nil {
+		return err
+	}
+	defer closeClient()
+
+	if err := client.Remove(path.Join(s.dir(), path.Base(key))); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultTimeout bounds connecting to and listing a storage backend
+const defaultTimeout = 30 * time.Second
+
+// Object is a file found in a storage backend
+type Object struct {
+	// Key is the object name relative to the storage path
+	Key string
+	// Size is the object size in bytes
+	Size int64
+	// LastModified is when the object was written
+	LastModified time.Time
+	// Checksum is "<algorithm>:<hex digest>" when the backend reports one
+	Checksum string
+}
+
+// Client lists and deletes the files gobackup uploaded to a storage backend
+type Client interface {
+	// List returns the files directly under the storage path
+	List(ctx context.Context) ([]Object, error)
+	// Delete removes the file with the given key. Missing files are not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// New returns a Client for a Storage of the given type. config is the Storage
+// config with secret references resolved, keyed like gobackup's options.
+func New(storageType string, config map[string]interface{}) (Client, error) {
+	switch storageType {
+	case "local":
+		return newLocal(config)
+	case "sftp", "scp":
+		return newSFTP(config)
+	case "ftp":
+		return newFTP(config)
+	case "webdav":
+		return newWebDAV(config)
+	default:
+		if _, ok := s3Compatible[storageType]; ok {
+			return newS3(storageType, config)
+		}
+		return nil, fmt.Errorf("listing %s storage is not supported", storageType)
+	}
+}
+
+// IsSupported reports whether New can list a Storage of the given type
+func IsSupported(storageType string) bool {
+	switch storageType {
+	case "local", "sftp", "scp", "ftp", "webdav":
+		return true
+	}
+	_, ok := s3Compatible[storageType]
+	return ok
+}
+
+// configString returns a config value as a string, or "" when it is not set
+func configString(config map[string]interface{}, key string) string {
+	value, ok := config[key]
+	if !ok || value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+// configInt returns a config value as an int, or def when it is not set
+func configInt(config map[string]interface{}, key string, def int) int {
+	value := configString(config, key)
+	if value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+// configBool returns a config value as a bool, false when it is not set
+func configBool(config map[string]interface{}, key string) bool {
+	value, _ := strconv.ParseBool(configString(config, key))
+	return value
+}
+
+// configPath returns the storage path without surrounding slashes
+func configPath(config map[string]interface{}) string {
+	return strings.Trim(configString(config, "path"), "/")
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+
+	"github.com/studio-b12/gowebdav"
+)
+
+// webdav lists a collection on a WebDAV server
+type webdav struct {
+	client *gowebdav.Client
+	path   string
+}
+
+func newWebDAV(config map[string]interface{}) (*webdav, error) {
+	root := configString(config, "root")
+	if root == "" {
+		return nil, fmt.Errorf("webdav storage requires a root URL")
+	}
+	client := gowebdav.NewClient(root, configString(config, "username"), configString(config, "password"))
+	client.SetTimeout(defaultTimeout)
+	return &webdav{client: client, path: "/" + configPath(config)}, nil
+}
+
+func (w *webdav) List(ctx context.Context) ([]Object, error) {
+	entries, err := w.client.ReadDir(w.path)
+	if gowebdav.IsErrNotFound(err) {
+		return nil, fmt.Errorf("failed to list collection %s: %w", w.path, fs.ErrNotExist)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collection %s: %w", w.path, err)
+	}
+
+	objects := []Object{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		object := Object{
+			Key:          entry.Name(),
+			Size:         entry.Size(),
+			LastModified: entry.ModTime(),
+		}
+		if file, ok := entry.(gowebdav.File); ok && file.ETag() != "" {
+			object.Checksum = "etag:" + file.ETag()
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+func (w *webdav) Delete(ctx context.Context, key string) error {
+	// gowebdav treats a missing file as deleted
+	if err := w.client.Remove(path.Join(w.path, path.Base(key))); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	return nil
+}